@@ -0,0 +1,88 @@
+package aggregator
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// accInputHashCache caches the acc input hashes read from L1, which only
+// change if L1 reorgs. The proving of long ranges reads the same ones many
+// times. L1 only stores the acc input hash of the last batch of each
+// sequence and returns a zero hash for the others, which are not cached so
+// they are read again once the batch closes a sequence.
+type accInputHashCache struct {
+	etherman
+	size   int
+	hashes map[uint64]common.Hash
+	mutex  sync.Mutex
+}
+
+func newAccInputHashCache(etherman etherman, size int) *accInputHashCache {
+	return &accInputHashCache{
+		etherman: etherman,
+		size:     size,
+		hashes:   make(map[uint64]common.Hash),
+	}
+}
+
+// GetBatchAccInputHash returns the acc input hash of the batch, reading it
+// from L1 if it is not cached
+func (c *accInputHashCache) GetBatchAccInputHash(ctx context.Context, batchNumber uint64) (common.Hash, error) {
+	if c.size <= 0 {
+		return c.etherman.GetBatchAccInputHash(ctx, batchNumber)
+	}
+
+	c.mutex.Lock()
+	hash, ok := c.hashes[batchNumber]
+	c.mutex.Unlock()
+	if ok {
+		return hash, nil
+	}
+
+	hash, err := c.etherman.GetBatchAccInputHash(ctx, batchNumber)
+	if err != nil || hash == (common.Hash{}) {
+		return hash, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.hashes[batchNumber] = hash
+	if len(c.hashes) > c.size {
+		c.evictOldest(len(c.hashes) - c.size)
+	}
+	return hash, nil
+}
+
+// invalidateFrom drops the cached acc input hashes of the batches from the
+// given one on, whose sequences may have been replaced
+func (c *accInputHashCache) invalidateFrom(batchNumber uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var dropped int
+	for n := range c.hashes {
+		if n >= batchNumber {
+			delete(c.hashes, n)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		log.Infof("Dropped %d cached acc input hashes from batch %d", dropped, batchNumber)
+	}
+}
+
+// evictOldest drops the n cached acc input hashes of the lowest batches,
+// which are already verified
+func (c *accInputHashCache) evictOldest(n int) {
+	batchNumbers := make([]uint64, 0, len(c.hashes))
+	for batchNumber := range c.hashes {
+		batchNumbers = append(batchNumbers, batchNumber)
+	}
+	sort.Slice(batchNumbers, func(i, j int) bool { return batchNumbers[i] < batchNumbers[j] })
+	for _, batchNumber := range batchNumbers[:n] {
+		delete(c.hashes, batchNumber)
+	}
+}
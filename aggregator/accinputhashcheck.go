@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/accinputhash"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-synchronizer-l1/synchronizer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/iden3/go-iden3-crypto/keccak256"
+)
+
+// ErrAccInputHashMismatch is returned when the acc input hash computed from the
+// local batch data does not match the one stored on L1.
+var ErrAccInputHashMismatch = errors.New("acc input hash does not match L1")
+
+// verifyAccInputHash checks the acc input hash of the batch against the one
+// stored on L1 before the batch is proven. L1 only stores the acc input hash of
+// the last batch of each sequence, other batches are checked transitively when
+// the last batch of their sequence is. On mismatch, the batch is compared with
+// the data decoded by the L1 synchronizer to report the diverging fields.
+func (a *Aggregator) verifyAccInputHash(ctx context.Context, batch *state.Batch, sequence *synchronizer.SequencedBatches) error {
+	// the injected batch is built from the genesis, nothing to compare with
+	if batch.BatchNumber <= 1 || batch.BatchNumber != sequence.ToBatchNumber {
+		return nil
+	}
+
+	l1AccInputHash, err := a.etherman.GetBatchAccInputHash(ctx, batch.BatchNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get acc input hash of batch %d from L1: %w", batch.BatchNumber, err)
+	}
+
+	oldBatch, _, err := a.state.GetBatch(ctx, batch.BatchNumber-1, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get batch %d: %w", batch.BatchNumber-1, err)
+	}
+
+	accInputHash, err := accinputhash.CalculateAccInputHash(oldBatch.AccInputHash, batch.BatchL2Data, batch.L1InfoRoot, uint64(batch.Timestamp.Unix()), batch.Coinbase, common.Hash{})
+	if err != nil {
+		return fmt.Errorf("failed to calculate acc input hash of batch %d: %w", batch.BatchNumber, err)
+	}
+	if accInputHash == l1AccInputHash {
+		return nil
+	}
+
+	divergences, err := a.accInputHashDivergences(ctx, batch, sequence)
+	if err != nil {
+		log.Warnf("Failed to compare batch %d with L1 data: %v", batch.BatchNumber, err)
+	}
+	if len(divergences) == 0 {
+		// all the batch fields match L1, the previous batches diverge
+		divergences = append(divergences, fmt.Sprintf("oldAccInputHash (batch %d): %s", oldBatch.BatchNumber, oldBatch.AccInputHash))
+	}
+
+	return fmt.Errorf("%w: batch %d computed %s, L1 %s, diverging fields: %s",
+		ErrAccInputHashMismatch, batch.BatchNumber, accInputHash, l1AccInputHash, strings.Join(divergences, "; "))
+}
+
+// accInputHashDivergences returns the fields of the batch that differ from the
+// data decoded by the L1 synchronizer from the sequencing transaction.
+func (a *Aggregator) accInputHashDivergences(ctx context.Context, batch *state.Batch, sequence *synchronizer.SequencedBatches) ([]string, error) {
+	virtualBatch, err := a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, batch.BatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	if virtualBatch == nil {
+		return nil, state.ErrNotFound
+	}
+
+	var divergences []string
+	if !bytes.Equal(batch.BatchL2Data, virtualBatch.BatchL2Data) {
+		divergences = append(divergences, fmt.Sprintf("batchL2Data: local hash %s, L1 hash %s",
+			common.BytesToHash(keccak256.Hash(batch.BatchL2Data)), common.BytesToHash(keccak256.Hash(virtualBatch.BatchL2Data))))
+	}
+	if batch.Coinbase != virtualBatch.Coinbase {
+		divergences = append(divergences, fmt.Sprintf("coinbase: local %s, L1 %s", batch.Coinbase, virtualBatch.Coinbase))
+	}
+	if batch.L1InfoRoot != sequence.L1InfoRoot {
+		divergences = append(divergences, fmt.Sprintf("l1InfoRoot: local %s, L1 %s", batch.L1InfoRoot, sequence.L1InfoRoot))
+	}
+	if batch.Timestamp.Unix() != sequence.Timestamp.Unix() {
+		divergences = append(divergences, fmt.Sprintf("timestamp: local %d, L1 %d", batch.Timestamp.Unix(), sequence.Timestamp.Unix()))
+	}
+	if batch.ForkID != virtualBatch.ForkID {
+		divergences = append(divergences, fmt.Sprintf("forkId: local %d, L1 %d", batch.ForkID, virtualBatch.ForkID))
+	}
+
+	return divergences, nil
+}
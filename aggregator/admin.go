@@ -0,0 +1,336 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	xlayermetrics "github.com/0xPolygonHermez/zkevm-aggregator/xlayer/metrics"
+)
+
+const (
+	// AdminProofsEndpoint is the prefix of the admin endpoints operating on proofs
+	AdminProofsEndpoint = "/admin/proofs/"
+	// AdminFinalProofsEndpoint is the admin endpoint to inject final proofs
+	AdminFinalProofsEndpoint = "/admin/finalproofs"
+	// AdminAuditLogEndpoint is the admin endpoint to query the audit log
+	AdminAuditLogEndpoint = "/admin/auditlog"
+	// AdminFailedProofsEndpoint is the admin endpoint to query the captured failed proofs
+	AdminFailedProofsEndpoint = "/admin/failedproofs"
+	// AdminETAEndpoint is the admin endpoint to query the proving backlog ETA
+	AdminETAEndpoint = "/admin/eta"
+	// AdminPublicInputsEndpoint is the admin endpoint to query the public inputs of the final proofs
+	AdminPublicInputsEndpoint = "/admin/publicinputs"
+
+	adminProofBlobSuffix   = "/blob"
+	adminProofBlobChunk    = 32 * 1024
+	adminReadHeaderTimeout = 10 * time.Second
+	adminMaxRequestBody    = 1 << 20
+	adminAuditLogLimit     = 100
+	adminAuditLogMaxLimit  = 1000
+)
+
+// startAdminServer creates the admin HTTP API server and serves it in the
+// background until the aggregator is stopped.
+func (a *Aggregator) startAdminServer() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(AdminProofsEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleAdminProofs))
+	mux.HandleFunc(AdminFinalProofsEndpoint, a.requireAdminRole(AdminRoleOperator, a.handleAdminInjectFinalProof))
+	mux.HandleFunc(AdminAuditLogEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleAdminAuditLog))
+	mux.HandleFunc(AdminFailedProofsEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleAdminFailedProofs))
+	mux.HandleFunc(AdminETAEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleAdminETA))
+	mux.HandleFunc(AdminPublicInputsEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleAdminPublicInputs))
+	mux.HandleFunc(ExplorerBatchesEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleExplorerBatches))
+	mux.HandleFunc(ExplorerProofsEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleExplorerProofs))
+	mux.HandleFunc(ExplorerSequencesEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleExplorerSequences))
+	mux.HandleFunc(ExplorerVerificationsEndpoint, a.requireAdminRole(AdminRoleViewer, a.handleExplorerVerifications))
+
+	if len(a.cfg.AdminAPI.APIKeys) == 0 {
+		log.Warn("No admin API keys configured, the admin API is not authenticated")
+	}
+
+	address := net.JoinHostPort(a.cfg.AdminAPI.Host, strconv.Itoa(a.cfg.AdminAPI.Port))
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to create tcp listener for admin API: %w", err)
+	}
+
+	// the server is set before serving so Stop always sees it
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: adminReadHeaderTimeout,
+	}
+	a.adminSrv = srv
+
+	go func() {
+		log.Infof("Admin API server listening on port %d", a.cfg.AdminAPI.Port)
+		if err := srv.Serve(lis); err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				log.Warn("Admin API server stopped")
+				return
+			}
+			log.Errorf("Closed http connection for admin API server: %v", err)
+		}
+	}()
+	return nil
+}
+
+// handleAdminProofs dispatches the requests under AdminProofsEndpoint.
+//
+//	GET /admin/proofs/{id}/blob
+func (a *Aggregator) handleAdminProofs(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	path := strings.TrimPrefix(r.URL.Path, AdminProofsEndpoint)
+	if !strings.HasSuffix(path, adminProofBlobSuffix) {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	proofID := strings.TrimSuffix(path, adminProofBlobSuffix)
+	if proofID == "" || strings.Contains(proofID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	a.streamProofBlob(w, r, proofID)
+}
+
+// streamProofBlob writes the proof identified by proofID to the response
+// using chunked transfer encoding. Recursive proofs are read from the state,
+// final proofs are served from the ones built by this aggregator instance.
+func (a *Aggregator) streamProofBlob(w http.ResponseWriter, r *http.Request, proofID string) {
+	log := log.WithFields("proofId", proofID)
+
+	var blob []byte
+	proof, err := a.state.GetProofByID(r.Context(), proofID, nil)
+	switch {
+	case err == nil:
+		if proof.GeneratingSince != nil && proof.Proof == "" {
+			http.Error(w, "proof is still being generated", http.StatusConflict)
+			return
+		}
+		blob = []byte(proof.Proof)
+	case errors.Is(err, state.ErrNotFound):
+		finalProof := a.getBuiltFinalProof(proofID)
+		if finalProof == nil {
+			http.NotFound(w, r)
+			return
+		}
+		blob, err = json.Marshal(finalProof)
+		if err != nil {
+			log.Errorf("Failed to serialize final proof: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	default:
+		log.Errorf("Failed to get proof: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", proofID+".json"))
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	for offset := 0; offset < len(blob); offset += adminProofBlobChunk {
+		end := offset + adminProofBlobChunk
+		if end > len(blob) {
+			end = len(blob)
+		}
+		if _, err := w.Write(blob[offset:end]); err != nil {
+			if !errors.Is(err, io.ErrClosedPipe) {
+				log.Warnf("Failed to write proof blob chunk: %v", err)
+			}
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	log.Infof("Proof blob served to %s, %d bytes", r.RemoteAddr, len(blob))
+}
+
+// handleAdminInjectFinalProof accepts a final proof produced out-of-band.
+//
+//	POST /admin/finalproofs
+func (a *Aggregator) handleAdminInjectFinalProof(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var injected InjectedFinalProof
+	if err := json.NewDecoder(io.LimitReader(r.Body, adminMaxRequestBody)).Decode(&injected); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode final proof: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err := a.InjectFinalProof(r.Context(), &injected)
+	a.audit(r, AuditActionInjectFinalProof, map[string]uint64{
+		"batchNumber":      injected.BatchNumber,
+		"batchNumberFinal": injected.BatchNumberFinal,
+	}, err)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusAccepted)
+	case errors.Is(err, ErrInvalidInjectedProof):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrProofVerificationInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Errorf("Failed to inject final proof: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// handleAdminAuditLog returns the latest operator actions, newest first.
+//
+//	GET /admin/auditlog?action={action}&limit={limit}
+func (a *Aggregator) handleAdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := adminLimit(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := a.state.GetAuditLog(r.Context(), r.URL.Query().Get("action"), limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get audit log: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeAdminJSON(w, entries)
+}
+
+// handleAdminFailedProofs returns the latest captured failed proofs, newest
+// first.
+//
+//	GET /admin/failedproofs?limit={limit}
+func (a *Aggregator) handleAdminFailedProofs(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := adminLimit(w, r)
+	if !ok {
+		return
+	}
+
+	failedProofs, err := a.state.GetFailedProofs(r.Context(), limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get failed proofs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeAdminJSON(w, failedProofs)
+}
+
+// handleAdminETA returns the estimated time for the unproven batches to be
+// proven. The ETA of every unproven batch is only included when requested.
+//
+//	GET /admin/eta?batches={true|false}
+func (a *Aggregator) handleAdminETA(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	eta, err := a.estimateBacklog(r.Context())
+	if errors.Is(err, ErrNoProvingTimes) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		log.Errorf("Failed to estimate the proving backlog: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if withBatches, _ := strconv.ParseBool(r.URL.Query().Get("batches")); !withBatches {
+		eta.BatchETAs = nil
+	}
+
+	writeAdminJSON(w, eta)
+}
+
+// handleAdminPublicInputs returns the public inputs of the latest final
+// proofs, newest first, optionally only the ones of the final proofs
+// including the given batch.
+//
+//	GET /admin/publicinputs?batch={batchNumber}&limit={limit}
+func (a *Aggregator) handleAdminPublicInputs(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := adminLimit(w, r)
+	if !ok {
+		return
+	}
+
+	var batchNumber uint64
+	if value := r.URL.Query().Get("batch"); value != "" {
+		var err error
+		batchNumber, err = strconv.ParseUint(value, 10, 64) //nolint:gomnd
+		if err != nil {
+			http.Error(w, "invalid batch number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	publicInputs, err := a.state.GetFinalProofPublicInputs(r.Context(), batchNumber, limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get final proof public inputs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeAdminJSON(w, publicInputs)
+}
+
+// adminLimit parses the limit query parameter, writing the error response
+// and returning false if it is not valid.
+func adminLimit(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	limit := uint64(adminAuditLogLimit)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseUint(value, 10, 64) //nolint:gomnd
+		if err != nil || limit == 0 || limit > adminAuditLogMaxLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", adminAuditLogMaxLimit), http.StatusBadRequest)
+			return 0, false
+		}
+	}
+	return limit, true
+}
+
+// writeAdminJSON writes v as the JSON body of a successful response.
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("Failed to write admin API response: %v", err)
+	}
+}
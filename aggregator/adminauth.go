@@ -0,0 +1,90 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminRole is the role of an admin API key, each role is allowed to perform
+// the actions of the roles below it.
+type AdminRole string
+
+const (
+	// AdminRoleViewer can only read, e.g. dashboards
+	AdminRoleViewer AdminRole = "viewer"
+	// AdminRoleOperator can also perform operational actions, e.g. inject proofs
+	AdminRoleOperator AdminRole = "operator"
+	// AdminRoleAdmin can perform any action
+	AdminRoleAdmin AdminRole = "admin"
+
+	adminAPIKeyHeader = "X-API-Key"
+	bearerPrefix      = "Bearer "
+)
+
+// adminRoleLevels orders the roles from the least to the most privileged
+var adminRoleLevels = map[AdminRole]int{
+	AdminRoleViewer:   1,
+	AdminRoleOperator: 2, //nolint:gomnd
+	AdminRoleAdmin:    3, //nolint:gomnd
+}
+
+// allows returns true if the role is allowed to perform the actions of the
+// required role.
+func (r AdminRole) allows(required AdminRole) bool {
+	return adminRoleLevels[r] > 0 && adminRoleLevels[r] >= adminRoleLevels[required]
+}
+
+// adminAPIKeyFromRequest returns the API key sent in the X-API-Key header or
+// as a bearer token.
+func adminAPIKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(adminAPIKeyHeader); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, bearerPrefix) {
+		return strings.TrimPrefix(auth, bearerPrefix)
+	}
+	return ""
+}
+
+// authenticateAdmin returns the configured API key matching the request.
+func (a *Aggregator) authenticateAdmin(r *http.Request) (*AdminAPIKey, bool) {
+	key := adminAPIKeyFromRequest(r)
+	if key == "" {
+		return nil, false
+	}
+	for i := range a.cfg.AdminAPI.APIKeys {
+		apiKey := &a.cfg.AdminAPI.APIKeys[i]
+		if subtle.ConstantTimeCompare([]byte(apiKey.Key), []byte(key)) == 1 {
+			return apiKey, true
+		}
+	}
+	return nil, false
+}
+
+// requireAdminRole wraps an admin API handler so it is only served to
+// requests authenticated with an API key of at least the required role. The
+// name of the key is the principal recorded in the audit log. When no API key
+// is configured the admin API is open, as it was before authentication.
+func (a *Aggregator) requireAdminRole(required AdminRole, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(a.cfg.AdminAPI.APIKeys) == 0 {
+			handler(w, r)
+			return
+		}
+
+		apiKey, ok := a.authenticateAdmin(r)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if !apiKey.Role.allows(required) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), adminPrincipalKey{}, apiKey.Name)
+		handler(w, r.WithContext(ctx))
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
-	"github.com/0xPolygon/cdk-rpc/rpc"
 	"github.com/0xPolygon/cdk-rpc/types"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -21,19 +21,22 @@ type AgglayerClientInterface interface {
 
 // AggLayerClient is the client that will be used to interact with the AggLayer
 type AggLayerClient struct {
-	url string
+	url    string
+	client *http.Client
 }
 
-// NewAggLayerClient returns a client ready to be used
-func NewAggLayerClient(url string) *AggLayerClient {
+// NewAggLayerClient returns a client ready to be used, sending the requests
+// with the given HTTP client
+func NewAggLayerClient(url string, client *http.Client) *AggLayerClient {
 	return &AggLayerClient{
-		url: url,
+		url:    url,
+		client: client,
 	}
 }
 
 // SendTx sends a signed transaction to the AggLayer
 func (c *AggLayerClient) SendTx(signedTx SignedTx) (common.Hash, error) {
-	response, err := rpc.JSONRPCCall(c.url, "interop_sendTx", signedTx)
+	response, err := jsonRPCCall(context.Background(), c.client, c.url, "interop_sendTx", signedTx)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -59,7 +62,7 @@ func (c *AggLayerClient) WaitTxToBeMined(hash common.Hash, ctx context.Context)
 		case <-ctx.Done():
 			return errors.New("context finished before tx was mined")
 		case <-ticker.C:
-			response, err := rpc.JSONRPCCall(c.url, "interop_getTxStatus", hash)
+			response, err := jsonRPCCall(ctx, c.client, c.url, "interop_getTxStatus", hash)
 			if err != nil {
 				return err
 			}
@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"text/template"
 	"time"
 	"unicode"
 
@@ -33,6 +36,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-synchronizer-l1/state/entities"
 	"github.com/0xPolygonHermez/zkevm-synchronizer-l1/synchronizer"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
 	"google.golang.org/grpc"
 	grpchealth "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/peer"
@@ -50,6 +54,9 @@ type finalProofMsg struct {
 	proverID       string
 	recursiveProof *state.Proof
 	finalProof     *prover.FinalProof
+	// injected is true for the final proofs injected through the admin API,
+	// whose recursive proof is synthetic and never stored
+	injected bool
 }
 
 // Aggregator represents an aggregator
@@ -65,6 +72,11 @@ type Aggregator struct {
 	l1Syncr      synchronizer.Synchronizer
 	halted       atomic.Bool
 
+	// verifier the final proofs are settled against, submission is paused
+	// once it changes
+	rollupVerifier  *ethmanTypes.RollupVerifier
+	verifierChanged atomic.Bool
+
 	profitabilityChecker    aggregatorTxProfitabilityChecker
 	timeSendFinalProof      time.Time
 	timeCleanupLockedProofs types.Duration
@@ -72,6 +84,8 @@ type Aggregator struct {
 	timeSendFinalProofMutex *sync.RWMutex
 
 	// Data stream handling variables
+	streamProtocol         StreamProtocol
+	legacyStream           legacyStreamState
 	currentBatchStreamData []byte
 	currentStreamBatch     state.Batch
 	currentStreamBatchRaw  state.BatchRawV2
@@ -79,13 +93,48 @@ type Aggregator struct {
 
 	finalProof     chan finalProofMsg
 	verifyingProof bool
-
-	srv  *grpc.Server
-	ctx  context.Context
-	exit context.CancelFunc
+	// transaction holding the lock of the batch range of the final proof
+	// being built or verified, guarded by timeSendFinalProofMutex
+	finalProofRangeLock pgx.Tx
+
+	// last final proof built by this instance, kept to be served by the admin API
+	builtFinalProofID    string
+	builtFinalProof      *prover.FinalProof
+	builtFinalProofMutex *sync.RWMutex
+
+	// jobs the connected provers are working on, by prover id
+	connectedProvers atomic.Int64
+	proverJobs       map[string]*proverJob
+	proverJobsMutex  *sync.Mutex
+	// failure domains of the connected provers and of the failed batch proofs
+	failureDomains *failureDomains
+	// batch limit of the aggregations after a prover ran out of resources
+	aggregationSplit *aggregationSplit
+	// sequences read from the RollupManager, when it is the sequence source
+	l1Sequences *l1Sequences
+	// last observed state of the proving pipeline, to detect stalls
+	watchdog *pipelineWatchdog
+	// latest proving times, used to estimate the proving backlog ETA
+	provingTimes *provingTimes
+
+	srv      *grpc.Server
+	adminSrv *http.Server
+	ctx      context.Context
+	exit     context.CancelFunc
 
 	sequencerPrivateKey *ecdsa.PrivateKey
 	aggLayerClient      AgglayerClientInterface
+	// client of the outgoing HTTP requests, going through the configured proxy
+	httpClient *http.Client
+
+	// additional L1 endpoints the verification txs are sent to
+	l1Broadcasters []*l1Broadcaster
+	// secondary destinations of the verified final proofs
+	verificationTargets []verificationTarget
+	// template choosing the beneficiary of each final proof, nil to use the sender
+	beneficiaryTemplate *template.Template
+	// acc input hashes read from L1, wrapping etherman
+	accInputHashes *accInputHashCache
 }
 
 // New creates a new aggregator.
@@ -147,13 +196,18 @@ func New(
 		log.Fatalf("failed to create synchronizer client, error: %v", err)
 	}
 
+	httpClient, err := cfg.Proxy.HTTPClient(0)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		aggLayerClient      AgglayerClientInterface
 		sequencerPrivateKey *ecdsa.PrivateKey
 	)
 
 	if cfg.SettlementBackend == AggLayer {
-		aggLayerClient = NewAggLayerClient(cfg.AggLayerURL)
+		aggLayerClient = NewAggLayerClient(cfg.AggLayerURL, httpClient)
 
 		sequencerPrivateKey, err = newKeyFromKeystore(cfg.SequencerPrivateKey)
 		if err != nil {
@@ -161,21 +215,46 @@ func New(
 		}
 	}
 
+	verificationTargets, err := newVerificationTargets(cfg.VerificationTargets, cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	beneficiaryTemplate, err := newBeneficiaryTemplate(cfg.Beneficiary)
+	if err != nil {
+		return nil, err
+	}
+
+	accInputHashes := newAccInputHashCache(etherman, cfg.AccInputHashCacheSize)
+
 	a := &Aggregator{
 		cfg:                     cfg,
 		state:                   stateInterface,
-		etherman:                etherman,
+		etherman:                accInputHashes,
+		accInputHashes:          accInputHashes,
 		ethTxManager:            ethTxManager,
 		streamClient:            streamClient,
 		l1Syncr:                 l1Syncr,
 		profitabilityChecker:    profitabilityChecker,
 		stateDBMutex:            &sync.Mutex{},
 		timeSendFinalProofMutex: &sync.RWMutex{},
+		builtFinalProofMutex:    &sync.RWMutex{},
+		proverJobs:              make(map[string]*proverJob),
+		proverJobsMutex:         &sync.Mutex{},
+		failureDomains:          newFailureDomains(),
+		aggregationSplit:        &aggregationSplit{},
+		l1Sequences:             &l1Sequences{},
+		watchdog:                newPipelineWatchdog(),
+		provingTimes:            newProvingTimes(cfg.ETA.Window),
 		timeCleanupLockedProofs: cfg.CleanupLockedProofsInterval,
 		finalProof:              make(chan finalProofMsg),
 		currentBatchStreamData:  []byte{},
 		aggLayerClient:          aggLayerClient,
 		sequencerPrivateKey:     sequencerPrivateKey,
+		httpClient:              httpClient,
+		l1Broadcasters:          newL1Broadcasters(cfg.BroadcastL1URLs, cfg.Proxy),
+		verificationTargets:     verificationTargets,
+		beneficiaryTemplate:     beneficiaryTemplate,
 	}
 
 	// Set function to handle the batches from the data stream
@@ -194,7 +273,11 @@ func (a *Aggregator) handleReorg(reorgData synchronizer.ReorgExecutionResult) {
 	lastVBatchNumber, err := a.l1Syncr.GetLastestVirtualBatchNumber(ctx)
 	if err != nil {
 		log.Errorf("Error getting last virtual batch number: %v", err)
+		a.accInputHashes.invalidateFrom(0)
+		a.l1Sequences.dropFrom(0)
 	} else {
+		a.accInputHashes.invalidateFrom(lastVBatchNumber + 1)
+		a.l1Sequences.dropFrom(lastVBatchNumber + 1)
 		err = a.state.DeleteBatchesNewerThanBatchNumber(ctx, lastVBatchNumber, nil)
 		if err != nil {
 			log.Errorf("Error deleting batches newer than batch number %d: %v", lastVBatchNumber, err)
@@ -210,200 +293,208 @@ func (a *Aggregator) handleReorg(reorgData synchronizer.ReorgExecutionResult) {
 }
 
 func (a *Aggregator) handleReceivedDataStream(entry *datastreamer.FileEntry, client *datastreamer.StreamClient, server *datastreamer.StreamServer) error {
+	if a.halted.Load() || entry.Type == datastreamer.EntryType(datastreamer.EtBookmark) {
+		return nil
+	}
+	if a.streamProtocol == StreamProtocolLegacy {
+		return a.handleLegacyStreamEntry(entry)
+	}
+	return a.processStreamEntry(entry)
+}
+
+// processStreamEntry builds the batches from the entries of the v2 data
+// stream protocol, storing each one when its end entry is received.
+func (a *Aggregator) processStreamEntry(entry *datastreamer.FileEntry) error {
 	ctx := context.Background()
 	forcedBlockhashL1 := common.Hash{}
 
-	if !a.halted.Load() {
-		if entry.Type != datastreamer.EntryType(datastreamer.EtBookmark) {
-			a.currentBatchStreamData = append(a.currentBatchStreamData, entry.Encode()...)
+	a.currentBatchStreamData = append(a.currentBatchStreamData, entry.Encode()...)
 
-			switch entry.Type {
-			case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_BATCH_START):
-				batch := &datastream.BatchStart{}
-				err := proto.Unmarshal(entry.Data, batch)
-				if err != nil {
-					log.Errorf("Error unmarshalling batch: %v", err)
-					return err
-				}
+	switch entry.Type {
+	case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_BATCH_START):
+		batch := &datastream.BatchStart{}
+		err := proto.Unmarshal(entry.Data, batch)
+		if err != nil {
+			log.Errorf("Error unmarshalling batch: %v", err)
+			return err
+		}
 
-				a.currentStreamBatch.BatchNumber = batch.Number
-				a.currentStreamBatch.ChainID = batch.ChainId
-				a.currentStreamBatch.ForkID = batch.ForkId
-				a.currentStreamBatch.Type = batch.Type
-			case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_BATCH_END):
-				batch := &datastream.BatchEnd{}
-				err := proto.Unmarshal(entry.Data, batch)
-				if err != nil {
-					log.Errorf("Error unmarshalling batch: %v", err)
-					return err
-				}
+		a.currentStreamBatch.BatchNumber = batch.Number
+		a.currentStreamBatch.ChainID = batch.ChainId
+		a.currentStreamBatch.ForkID = batch.ForkId
+		a.currentStreamBatch.Type = batch.Type
+	case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_BATCH_END):
+		batch := &datastream.BatchEnd{}
+		err := proto.Unmarshal(entry.Data, batch)
+		if err != nil {
+			log.Errorf("Error unmarshalling batch: %v", err)
+			return err
+		}
 
-				a.currentStreamBatch.LocalExitRoot = common.BytesToHash(batch.LocalExitRoot)
-				a.currentStreamBatch.StateRoot = common.BytesToHash(batch.StateRoot)
+		a.currentStreamBatch.LocalExitRoot = common.BytesToHash(batch.LocalExitRoot)
+		a.currentStreamBatch.StateRoot = common.BytesToHash(batch.StateRoot)
 
-				// Add last block (if any) to the current batch
-				if a.currentStreamL2Block.BlockNumber != 0 {
-					a.currentStreamBatchRaw.Blocks = append(a.currentStreamBatchRaw.Blocks, a.currentStreamL2Block)
-				}
+		// Add last block (if any) to the current batch
+		if a.currentStreamL2Block.BlockNumber != 0 {
+			a.currentStreamBatchRaw.Blocks = append(a.currentStreamBatchRaw.Blocks, a.currentStreamL2Block)
+		}
 
-				// Save Current Batch
-				if a.currentStreamBatch.BatchNumber != 0 {
-					var batchl2Data []byte
+		// Save Current Batch
+		if a.currentStreamBatch.BatchNumber != 0 {
+			var batchl2Data []byte
 
-					// Get batchl2Data from L1
-					virtualBatch, err := a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
-					if err != nil && !errors.Is(err, entities.ErrNotFound) {
-						log.Errorf("Error getting virtual batch: %v", err)
-						return err
-					}
+			// Get batchl2Data from L1
+			virtualBatch, err := a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
+			if err != nil && !errors.Is(err, entities.ErrNotFound) {
+				log.Errorf("Error getting virtual batch: %v", err)
+				return err
+			}
 
-					for errors.Is(err, entities.ErrNotFound) {
-						log.Debug("Waiting for virtual batch to be available")
-						time.Sleep(a.cfg.RetryTime.Duration)
-						virtualBatch, err = a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
+			for errors.Is(err, entities.ErrNotFound) {
+				log.Debug("Waiting for virtual batch to be available")
+				time.Sleep(a.cfg.RetryTime.Duration)
+				virtualBatch, err = a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
 
-						if err != nil && !errors.Is(err, entities.ErrNotFound) {
-							log.Errorf("Error getting virtual batch: %v", err)
-							return err
-						}
-					}
+				if err != nil && !errors.Is(err, entities.ErrNotFound) {
+					log.Errorf("Error getting virtual batch: %v", err)
+					return err
+				}
+			}
 
-					// Encode batch
-					if a.currentStreamBatch.Type != datastream.BatchType_BATCH_TYPE_INVALID {
-						batchl2Data, err = state.EncodeBatchV2(&a.currentStreamBatchRaw)
-						if err != nil {
-							log.Errorf("Error encoding batch: %v", err)
-							return err
-						}
-					}
+			// Encode batch
+			if a.currentStreamBatch.Type != datastream.BatchType_BATCH_TYPE_INVALID {
+				batchl2Data, err = state.EncodeBatchV2(&a.currentStreamBatchRaw)
+				if err != nil {
+					log.Errorf("Error encoding batch: %v", err)
+					return err
+				}
+			}
 
-					// If the batch is marked as Invalid in the DS we enforce retrieve the data from L1
-					if a.cfg.UseL1BatchData || a.currentStreamBatch.Type == datastream.BatchType_BATCH_TYPE_INVALID {
-						a.currentStreamBatch.BatchL2Data = virtualBatch.BatchL2Data
-					} else {
-						a.currentStreamBatch.BatchL2Data = batchl2Data
-					}
+			// If the batch is marked as Invalid in the DS we enforce retrieve the data from L1
+			if a.cfg.UseL1BatchData || a.currentStreamBatch.Type == datastream.BatchType_BATCH_TYPE_INVALID {
+				a.currentStreamBatch.BatchL2Data = virtualBatch.BatchL2Data
+			} else {
+				a.currentStreamBatch.BatchL2Data = batchl2Data
+			}
 
-					// Compare BatchL2Data from L1 and DataStream
-					if common.Bytes2Hex(batchl2Data) != common.Bytes2Hex(virtualBatch.BatchL2Data) && a.currentStreamBatch.Type != datastream.BatchType_BATCH_TYPE_INJECTED {
-						log.Warnf("BatchL2Data from L1 and data stream are different for batch %d", a.currentStreamBatch.BatchNumber)
+			// Compare BatchL2Data from L1 and DataStream
+			if common.Bytes2Hex(batchl2Data) != common.Bytes2Hex(virtualBatch.BatchL2Data) && a.currentStreamBatch.Type != datastream.BatchType_BATCH_TYPE_INJECTED {
+				log.Warnf("BatchL2Data from L1 and data stream are different for batch %d", a.currentStreamBatch.BatchNumber)
 
-						if a.currentStreamBatch.Type == datastream.BatchType_BATCH_TYPE_INVALID {
-							log.Warnf("Batch is marked as invalid in data stream")
-						} else {
-							log.Warnf("DataStream BatchL2Data:%v", common.Bytes2Hex(batchl2Data))
-						}
-						log.Warnf("L1 BatchL2Data:%v", common.Bytes2Hex(virtualBatch.BatchL2Data))
-					}
+				if a.currentStreamBatch.Type == datastream.BatchType_BATCH_TYPE_INVALID {
+					log.Warnf("Batch is marked as invalid in data stream")
+				} else {
+					log.Warnf("DataStream BatchL2Data:%v", common.Bytes2Hex(batchl2Data))
+				}
+				log.Warnf("L1 BatchL2Data:%v", common.Bytes2Hex(virtualBatch.BatchL2Data))
+			}
 
-					// Ger L1InfoRoot
-					sequence, err := a.l1Syncr.GetSequenceByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
-					if err != nil {
-						log.Errorf("Error getting sequence: %v", err)
-						return err
-					}
+			// Ger L1InfoRoot
+			sequence, err := a.l1Syncr.GetSequenceByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
+			if err != nil {
+				log.Errorf("Error getting sequence: %v", err)
+				return err
+			}
 
-					for sequence == nil {
-						log.Debug("Waiting for sequence to be available")
-						time.Sleep(a.cfg.RetryTime.Duration)
-						sequence, err = a.l1Syncr.GetSequenceByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
-						if err != nil {
-							log.Errorf("Error getting sequence: %v", err)
-							return err
-						}
-					}
+			for sequence == nil {
+				log.Debug("Waiting for sequence to be available")
+				time.Sleep(a.cfg.RetryTime.Duration)
+				sequence, err = a.l1Syncr.GetSequenceByBatchNumber(ctx, a.currentStreamBatch.BatchNumber)
+				if err != nil {
+					log.Errorf("Error getting sequence: %v", err)
+					return err
+				}
+			}
 
-					a.currentStreamBatch.L1InfoRoot = sequence.L1InfoRoot
-					a.currentStreamBatch.Timestamp = sequence.Timestamp
+			a.currentStreamBatch.L1InfoRoot = sequence.L1InfoRoot
+			a.currentStreamBatch.Timestamp = sequence.Timestamp
 
-					// Calculate Acc Input Hash
-					oldBatch, _, err := a.state.GetBatch(ctx, a.currentStreamBatch.BatchNumber-1, nil)
-					if err != nil {
-						log.Errorf("Error getting batch %d: %v", a.currentStreamBatch.BatchNumber-1, err)
-						return err
-					}
+			// Calculate Acc Input Hash
+			oldBatch, _, err := a.state.GetBatch(ctx, a.currentStreamBatch.BatchNumber-1, nil)
+			if err != nil {
+				log.Errorf("Error getting batch %d: %v", a.currentStreamBatch.BatchNumber-1, err)
+				return err
+			}
 
-					// Injected Batch
-					if a.currentStreamBatch.BatchNumber == 1 {
-						l1Block, err := a.l1Syncr.GetL1BlockByNumber(ctx, virtualBatch.BlockNumber)
-						if err != nil {
-							log.Errorf("Error getting L1 block: %v", err)
-							return err
-						}
+			// Injected Batch
+			if a.currentStreamBatch.BatchNumber == 1 {
+				l1Block, err := a.l1Syncr.GetL1BlockByNumber(ctx, virtualBatch.BlockNumber)
+				if err != nil {
+					log.Errorf("Error getting L1 block: %v", err)
+					return err
+				}
 
-						forcedBlockhashL1 = l1Block.ParentHash
-						a.currentStreamBatch.L1InfoRoot = a.currentStreamBatch.GlobalExitRoot
-					}
+				forcedBlockhashL1 = l1Block.ParentHash
+				a.currentStreamBatch.L1InfoRoot = a.currentStreamBatch.GlobalExitRoot
+			}
 
-					accInputHash, err := accinputhash.CalculateAccInputHash(oldBatch.AccInputHash, a.currentStreamBatch.BatchL2Data, a.currentStreamBatch.L1InfoRoot, uint64(a.currentStreamBatch.Timestamp.Unix()), a.currentStreamBatch.Coinbase, forcedBlockhashL1)
-					if err != nil {
-						log.Errorf("Error calculating acc input hash: %v", err)
-						return err
-					}
+			accInputHash, err := accinputhash.CalculateAccInputHash(oldBatch.AccInputHash, a.currentStreamBatch.BatchL2Data, a.currentStreamBatch.L1InfoRoot, uint64(a.currentStreamBatch.Timestamp.Unix()), a.currentStreamBatch.Coinbase, forcedBlockhashL1)
+			if err != nil {
+				log.Errorf("Error calculating acc input hash: %v", err)
+				return err
+			}
 
-					a.currentStreamBatch.AccInputHash = accInputHash
+			a.currentStreamBatch.AccInputHash = accInputHash
 
-					err = a.state.AddBatch(ctx, &a.currentStreamBatch, a.currentBatchStreamData, nil)
-					if err != nil {
-						log.Errorf("Error adding batch: %v", err)
-						return err
-					}
-				}
+			err = a.state.AddBatch(ctx, &a.currentStreamBatch, a.currentBatchStreamData, nil)
+			if err != nil {
+				log.Errorf("Error adding batch: %v", err)
+				return err
+			}
+		}
 
-				// Reset current batch data
-				a.currentBatchStreamData = []byte{}
-				a.currentStreamBatchRaw = state.BatchRawV2{
-					Blocks: make([]state.L2BlockRaw, 0),
-				}
-				a.currentStreamL2Block = state.L2BlockRaw{}
+		// Reset current batch data
+		a.currentBatchStreamData = []byte{}
+		a.currentStreamBatchRaw = state.BatchRawV2{
+			Blocks: make([]state.L2BlockRaw, 0),
+		}
+		a.currentStreamL2Block = state.L2BlockRaw{}
 
-			case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_L2_BLOCK):
-				// Add previous block (if any) to the current batch
-				if a.currentStreamL2Block.BlockNumber != 0 {
-					a.currentStreamBatchRaw.Blocks = append(a.currentStreamBatchRaw.Blocks, a.currentStreamL2Block)
-				}
-				// "Open" the new block
-				l2Block := &datastream.L2Block{}
-				err := proto.Unmarshal(entry.Data, l2Block)
-				if err != nil {
-					log.Errorf("Error unmarshalling L2Block: %v", err)
-					return err
-				}
+	case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_L2_BLOCK):
+		// Add previous block (if any) to the current batch
+		if a.currentStreamL2Block.BlockNumber != 0 {
+			a.currentStreamBatchRaw.Blocks = append(a.currentStreamBatchRaw.Blocks, a.currentStreamL2Block)
+		}
+		// "Open" the new block
+		l2Block := &datastream.L2Block{}
+		err := proto.Unmarshal(entry.Data, l2Block)
+		if err != nil {
+			log.Errorf("Error unmarshalling L2Block: %v", err)
+			return err
+		}
 
-				header := state.ChangeL2BlockHeader{
-					DeltaTimestamp:  l2Block.DeltaTimestamp,
-					IndexL1InfoTree: l2Block.L1InfotreeIndex,
-				}
+		header := state.ChangeL2BlockHeader{
+			DeltaTimestamp:  l2Block.DeltaTimestamp,
+			IndexL1InfoTree: l2Block.L1InfotreeIndex,
+		}
 
-				a.currentStreamL2Block.ChangeL2BlockHeader = header
-				a.currentStreamL2Block.Transactions = make([]state.L2TxRaw, 0)
-				a.currentStreamL2Block.BlockNumber = l2Block.Number
-				a.currentStreamBatch.L1InfoTreeIndex = l2Block.L1InfotreeIndex
-				a.currentStreamBatch.Coinbase = common.BytesToAddress(l2Block.Coinbase)
-				a.currentStreamBatch.GlobalExitRoot = common.BytesToHash(l2Block.GlobalExitRoot)
+		a.currentStreamL2Block.ChangeL2BlockHeader = header
+		a.currentStreamL2Block.Transactions = make([]state.L2TxRaw, 0)
+		a.currentStreamL2Block.BlockNumber = l2Block.Number
+		a.currentStreamBatch.L1InfoTreeIndex = l2Block.L1InfotreeIndex
+		a.currentStreamBatch.Coinbase = common.BytesToAddress(l2Block.Coinbase)
+		a.currentStreamBatch.GlobalExitRoot = common.BytesToHash(l2Block.GlobalExitRoot)
 
-			case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_TRANSACTION):
-				l2Tx := &datastream.Transaction{}
-				err := proto.Unmarshal(entry.Data, l2Tx)
-				if err != nil {
-					log.Errorf("Error unmarshalling L2Tx: %v", err)
-					return err
-				}
-				// New Tx raw
-				tx, err := state.DecodeTx(common.Bytes2Hex(l2Tx.Encoded))
-				if err != nil {
-					log.Errorf("Error decoding tx: %v", err)
-					return err
-				}
+	case datastreamer.EntryType(datastream.EntryType_ENTRY_TYPE_TRANSACTION):
+		l2Tx := &datastream.Transaction{}
+		err := proto.Unmarshal(entry.Data, l2Tx)
+		if err != nil {
+			log.Errorf("Error unmarshalling L2Tx: %v", err)
+			return err
+		}
+		// New Tx raw
+		tx, err := state.DecodeTx(common.Bytes2Hex(l2Tx.Encoded))
+		if err != nil {
+			log.Errorf("Error decoding tx: %v", err)
+			return err
+		}
 
-				l2TxRaw := state.L2TxRaw{
-					EfficiencyPercentage: uint8(l2Tx.EffectiveGasPricePercentage),
-					TxAlreadyEncoded:     false,
-					Tx:                   tx,
-				}
-				a.currentStreamL2Block.Transactions = append(a.currentStreamL2Block.Transactions, l2TxRaw)
-			}
+		l2TxRaw := state.L2TxRaw{
+			EfficiencyPercentage: uint8(l2Tx.EffectiveGasPricePercentage),
+			TxAlreadyEncoded:     false,
+			Tx:                   tx,
 		}
+		a.currentStreamL2Block.Transactions = append(a.currentStreamL2Block.Transactions, l2TxRaw)
 	}
 	return nil
 }
@@ -420,13 +511,16 @@ func (a *Aggregator) Start(ctx context.Context) error {
 
 	metrics.Register()
 
-	address := fmt.Sprintf("%s:%d", a.cfg.Host, a.cfg.Port)
+	address := net.JoinHostPort(a.cfg.Host, strconv.Itoa(a.cfg.Port))
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	a.srv = grpc.NewServer()
+	a.srv = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryServerInterceptor),
+		grpc.ChainStreamInterceptor(streamServerInterceptor),
+	)
 	prover.RegisterAggregatorServiceServer(a.srv, a)
 
 	healthService := newHealthChecker()
@@ -472,12 +566,73 @@ func (a *Aggregator) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Fail fast if the sender is not allowed to verify batches, a failure to
+	// query the role is not fatal and is checked again periodically
+	err = a.checkL1Permissions(ctx)
+	if errors.Is(err, ErrSenderNotTrustedAggregator) {
+		return err
+	} else if err != nil {
+		log.Warn(err)
+	}
+
+	if a.cfg.SettlementBackend != AggLayer {
+		err = a.initRollupVerifier(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get rollup verifier: %w", err)
+		}
+		if a.cfg.VerifierCheckInterval.Duration > 0 {
+			go a.watchRollupVerifier()
+		}
+
+		// reconcile the verification txs that were being sent when the
+		// aggregator stopped
+		unresolved, err := a.resolveL1Intents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve L1 intents: %w", err)
+		}
+		for _, intent := range unresolved {
+			log.Warnf("Verification tx for batches %d-%d with nonce %d may still be in flight, the range will not be sent again until it is resolved",
+				intent.BatchNumber, intent.BatchNumberFinal, intent.Nonce)
+		}
+	}
+
 	a.resetVerifyProofTime()
+	a.seedProvingTimes(ctx, lastVerifiedBatchNumber)
 
 	go a.cleanupLockedProofs()
 	go a.sendFinalProof()
 	go a.ethTxManager.Start()
 
+	if a.cfg.AdminAPI.Enabled {
+		if err := a.startAdminServer(); err != nil {
+			return err
+		}
+	}
+
+	if a.cfg.L1PermissionsCheckInterval.Duration > 0 && a.cfg.SettlementBackend != AggLayer {
+		go a.monitorL1Permissions()
+	}
+
+	if a.cfg.Preemption.Enabled {
+		go a.preemptForFinalProof()
+	}
+
+	if a.cfg.ETA.UpdateInterval.Duration > 0 {
+		go a.updateETAMetrics()
+	}
+
+	if a.cfg.ProofPartitioning.Enabled {
+		go a.maintainProofPartitions()
+	}
+
+	if a.cfg.Sequences.Source == SequenceSourceRollupManager {
+		go a.syncL1Sequences()
+	}
+
+	if a.cfg.Watchdog.Enabled {
+		go a.watchPipeline()
+	}
+
 	// Keep syncing L1
 	go func() {
 		err := a.l1Syncr.Sync(false)
@@ -492,17 +647,7 @@ func (a *Aggregator) Start(ctx context.Context) error {
 		log.Fatalf("failed to start stream client, error: %v", err)
 	}
 
-	bookMark := &datastream.BookMark{
-		Type:  datastream.BookmarkType_BOOKMARK_TYPE_BATCH,
-		Value: lastVerifiedBatchNumber + 1,
-	}
-
-	marshalledBookMark, err := proto.Marshal(bookMark)
-	if err != nil {
-		log.Fatalf("failed to marshal bookmark: %v", err)
-	}
-
-	err = a.streamClient.ExecCommandStartBookmark(marshalledBookMark)
+	err = a.startStreaming(lastVerifiedBatchNumber + 1)
 	if err != nil {
 		log.Fatalf("failed to connect to data stream: %v", err)
 	}
@@ -524,6 +669,11 @@ func (a *Aggregator) Start(ctx context.Context) error {
 func (a *Aggregator) Stop() {
 	a.exit()
 	a.srv.Stop()
+	if a.adminSrv != nil {
+		if err := a.adminSrv.Close(); err != nil {
+			log.Errorf("Failed to stop admin API server: %v", err)
+		}
+	}
 }
 
 // Channel implements the bi-directional communication channel between the
@@ -557,6 +707,25 @@ func (a *Aggregator) Channel(stream prover.AggregatorService_ChannelServer) erro
 		return err
 	}
 
+	quarantined, err := a.admitProver(prover)
+	if err != nil {
+		log.Warn(FirstToUpper(err.Error()))
+		return err
+	}
+	if quarantined {
+		log.Warn("Prover not allowed in the configuration, keeping it quarantined without jobs")
+		metrics.QuarantinedProver()
+		defer metrics.ReleasedQuarantinedProver()
+	}
+
+	a.connectedProvers.Add(1)
+	defer a.connectedProvers.Add(-1)
+	defer a.connectProverDomain(prover)()
+
+	ctx, endWatch := a.watchChannel(ctx)
+	defer endWatch()
+
+	lastJobTime := time.Now()
 	for {
 		select {
 		case <-a.ctx.Done():
@@ -567,6 +736,15 @@ func (a *Aggregator) Channel(stream prover.AggregatorService_ChannelServer) erro
 			return ctx.Err()
 
 		default:
+			if quarantined {
+				time.Sleep(a.cfg.RetryTime.Duration)
+				continue
+			}
+			if a.proverAtCapacity(prover) {
+				log.Debug("Provers with the same name are working on the maximum number of jobs")
+				time.Sleep(a.cfg.RetryTime.Duration)
+				continue
+			}
 			if !a.halted.Load() {
 				isIdle, err := prover.IsIdle()
 				if err != nil {
@@ -596,7 +774,15 @@ func (a *Aggregator) Channel(stream prover.AggregatorService_ChannelServer) erro
 						log.Errorf("Error trying to generate proof: %v", err)
 					}
 				}
-				if !proofGenerated {
+				if proofGenerated {
+					lastJobTime = time.Now()
+				} else if a.cfg.KeepWarm.Enabled && time.Since(lastJobTime) >= a.cfg.KeepWarm.IdleInterval.Duration {
+					lastJobTime = time.Now()
+					if err := a.keepWarm(ctx, prover); err != nil {
+						log.Error(FirstToUpper(err.Error()))
+						return err
+					}
+				} else {
 					// if no proof was generated (aggregated or batch) wait some time before retry
 					time.Sleep(a.cfg.RetryTime.Duration)
 				} // if proof was generated we retry immediately as probably we have more proofs to process
@@ -624,6 +810,12 @@ func (a *Aggregator) sendFinalProof() {
 
 			a.startProofVerification()
 
+			if a.verifierChanged.Load() {
+				log.Errorf("Rollup verifier changed, not settling final proof for batches %d-%d", proof.BatchNumber, proof.BatchNumberFinal)
+				a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
+				continue
+			}
+
 			finalBatch, _, err := a.state.GetBatch(ctx, proof.BatchNumberFinal, nil)
 			if err != nil {
 				log.Errorf("Failed to retrieve batch with number [%d]: %v", proof.BatchNumberFinal, err)
@@ -637,13 +829,39 @@ func (a *Aggregator) sendFinalProof() {
 				NewStateRoot:     finalBatch.StateRoot.Bytes(),
 			}
 
+			// batches reconstructed from L1 do not have roots, use the prover outputs
+			if !finalBatch.HasRoots() {
+				if msg.finalProof.Public == nil {
+					log.Errorf("Batch %d roots unknown and final proof without public outputs, not settling it", proof.BatchNumberFinal)
+					a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
+					continue
+				}
+				log.Warnf("Batch %d reconstructed from L1, using the final proof public outputs as roots", proof.BatchNumberFinal)
+				inputs.NewLocalExitRoot = msg.finalProof.Public.NewLocalExitRoot
+				inputs.NewStateRoot = msg.finalProof.Public.NewStateRoot
+			} else if finalBatch.StateRoot == (common.Hash{}) {
+				log.Errorf("Batch %d has no state root, not settling final proof", proof.BatchNumberFinal)
+				a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
+				continue
+			}
+
+			if a.cfg.ExitRootCheckEnabled {
+				if err := a.validateExitRoots(ctx, proof, &inputs); err != nil {
+					log.Errorf("Not settling final proof: %v", err)
+					a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
+					continue
+				}
+			}
+
+			a.recordFinalProofPublicInputs(ctx, proof, inputs)
+
 			switch a.cfg.SettlementBackend {
 			case AggLayer:
-				if success := a.settleWithAggLayer(ctx, proof, inputs); !success {
+				if success := a.settleWithAggLayer(ctx, msg, inputs); !success {
 					continue
 				}
 			default:
-				if success := a.settleDirect(ctx, proof, inputs); !success {
+				if success := a.settleDirect(ctx, msg, inputs); !success {
 					continue
 				}
 			}
@@ -656,8 +874,9 @@ func (a *Aggregator) sendFinalProof() {
 
 func (a *Aggregator) settleWithAggLayer(
 	ctx context.Context,
-	proof *state.Proof,
+	msg finalProofMsg,
 	inputs ethmanTypes.FinalProofInputs) bool {
+	proof := msg.recursiveProof
 	proofStrNo0x := strings.TrimPrefix(inputs.FinalProof.Proof, "0x")
 	proofBytes := common.Hex2Bytes(proofStrNo0x)
 	tx := Tx{
@@ -673,7 +892,7 @@ func (a *Aggregator) settleWithAggLayer(
 	signedTx, err := tx.Sign(a.sequencerPrivateKey)
 	if err != nil {
 		log.Errorf("failed to sign tx: %v", err)
-		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, proof)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
 
 		return false
 	}
@@ -682,7 +901,7 @@ func (a *Aggregator) settleWithAggLayer(
 	txHash, err := a.aggLayerClient.SendTx(*signedTx)
 	if err != nil {
 		log.Errorf("failed to send tx to the agglayer: %v", err)
-		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, proof)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
 
 		return false
 	}
@@ -693,51 +912,77 @@ func (a *Aggregator) settleWithAggLayer(
 	defer cancelFunc()
 	if err := a.aggLayerClient.WaitTxToBeMined(txHash, waitCtx); err != nil {
 		log.Errorf("agglayer didn't mine the tx: %v", err)
-		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, proof)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
 
 		return false
 	}
 
+	a.submitToVerificationTargets(proof, inputs, txHash)
+
 	return true
 }
 
 // settleDirect sends the final proof to the L1 smart contract directly.
 func (a *Aggregator) settleDirect(
 	ctx context.Context,
-	proof *state.Proof,
+	msg finalProofMsg,
 	inputs ethmanTypes.FinalProofInputs) bool {
+	proof := msg.recursiveProof
 	// add batch verification to be monitored
 	sender := common.HexToAddress(a.cfg.SenderAddress)
-	to, data, err := a.etherman.BuildTrustedVerifyBatchesTxData(proof.BatchNumber-1, proof.BatchNumberFinal, &inputs, sender)
+	to, data, err := a.etherman.BuildTrustedVerifyBatchesTxData(proof.BatchNumber-1, proof.BatchNumberFinal, &inputs, a.beneficiary(proof))
 	if err != nil {
 		log.Errorf("Error estimating batch verification to add to eth tx manager: %v", err)
-		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, proof)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
 		return false
 	}
 
-	monitoredTxID, err := a.ethTxManager.Add(ctx, to, nil, big.NewInt(0), data, a.cfg.GasOffset, nil)
+	monitoredTxID, err := a.addVerifyTxWithIntent(ctx, proof, to, data)
+	if errors.Is(err, ErrUnresolvedL1Intent) {
+		log.Warnf("Not sending batch verification: %v", err)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
+		return false
+	}
 	if err != nil {
 		log.Errorf("Error Adding TX to ethTxManager: %v", err)
 		mTxLogger := ethtxmanager.CreateLogger(monitoredTxID, sender, to)
 		mTxLogger.Errorf("Error to add batch verification tx to eth tx manager: %v", err)
-		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, proof)
+		a.handleFailureToAddVerifyBatchToBeMonitored(ctx, msg)
 		return false
 	}
 
+	if len(a.l1Broadcasters) > 0 {
+		broadcastCtx, cancelBroadcast := context.WithCancel(ctx)
+		defer cancelBroadcast()
+		go a.broadcastMonitoredTx(broadcastCtx, monitoredTxID)
+	}
+
 	// process monitored batch verifications before starting a next cycle
 	a.ethTxManager.ProcessPendingMonitoredTxs(ctx, func(result ethtxmanager.MonitoredTxResult) {
 		a.handleMonitoredTxResult(result)
 	})
 
+	result, err := a.ethTxManager.Result(ctx, monitoredTxID)
+	if err != nil {
+		log.Errorf("Failed to get the result of the batch verification tx: %v", err)
+	} else if result.Status == ethtxmanager.MonitoredTxStatusSafe || result.Status == ethtxmanager.MonitoredTxStatusFinalized {
+		a.submitToVerificationTargets(proof, inputs, minedTxHash(result))
+	}
+
 	return true
 }
 
-func (a *Aggregator) handleFailureToAddVerifyBatchToBeMonitored(ctx context.Context, proof *state.Proof) {
+func (a *Aggregator) handleFailureToAddVerifyBatchToBeMonitored(ctx context.Context, msg finalProofMsg) {
+	proof := msg.recursiveProof
 	log := log.WithFields("proofId", proof.ProofID, "batches", fmt.Sprintf("%d-%d", proof.BatchNumber, proof.BatchNumberFinal))
-	proof.GeneratingSince = nil
-	err := a.state.UpdateGeneratedProof(ctx, proof, nil)
-	if err != nil {
-		log.Errorf("Failed updating proof state (false): %v", err)
+	// the recursive proof of an injected final proof is not stored, writing
+	// it would overwrite the stored proof of the same batch range
+	if !msg.injected {
+		proof.GeneratingSince = nil
+		err := a.state.UpdateGeneratedProof(ctx, proof, nil)
+		if err != nil {
+			log.Errorf("Failed updating proof state (false): %v", err)
+		}
 	}
 	a.endProofVerification()
 }
@@ -752,8 +997,12 @@ func (a *Aggregator) buildFinalProof(ctx context.Context, prover proverInterface
 		"batches", fmt.Sprintf("%d-%d", proof.BatchNumber, proof.BatchNumberFinal),
 	)
 
+	defer a.trackProverJob(prover, finalProofJob, proof.BatchNumberFinal, nil)()
+
+	provingStart := time.Now()
 	finalProofID, err := prover.FinalProof(proof.Proof, a.cfg.SenderAddress)
 	if err != nil {
+		a.captureFailedProof(metrics.FinalProofLevel, proof, prover, proof.Proof, err)
 		return nil, fmt.Errorf("failed to get final proof id: %w", err)
 	}
 	proof.ProofID = finalProofID
@@ -763,8 +1012,12 @@ func (a *Aggregator) buildFinalProof(ctx context.Context, prover proverInterface
 
 	finalProof, err := prover.WaitFinalProof(ctx, *proof.ProofID)
 	if err != nil {
+		a.captureFailedProof(metrics.FinalProofLevel, proof, prover, proof.Proof, err)
 		return nil, fmt.Errorf("failed to get final proof from prover: %w", err)
 	}
+	metrics.ProofGenerated(metrics.FinalProofLevel, time.Since(provingStart))
+	a.provingTimes.observe(finalProofJob, time.Since(provingStart))
+	a.setBuiltFinalProof(*proof.ProofID, finalProof)
 
 	// mock prover sanity check
 	if string(finalProof.Public.NewStateRoot) == mockedStateRoot && string(finalProof.Public.NewLocalExitRoot) == mockedLocalExitRoot {
@@ -798,7 +1051,16 @@ func (a *Aggregator) tryBuildFinalProof(ctx context.Context, prover proverInterf
 	)
 	log.Debug("tryBuildFinalProof start")
 
+	if !a.proverAllowsJob(prover, finalProofJob) {
+		log.Debug("Prover labels do not allow final proofs")
+		return false, nil
+	}
+
 	var err error
+	if a.verifierChanged.Load() {
+		log.Debug("Rollup verifier changed, final proofs are paused")
+		return false, nil
+	}
 	if !a.canVerifyProof() {
 		log.Debug("Time to verify proof not reached or proof verification in progress")
 		return false, nil
@@ -851,9 +1113,24 @@ func (a *Aggregator) tryBuildFinalProof(ctx context.Context, prover proverInterf
 		"batches", fmt.Sprintf("%d-%d", proof.BatchNumber, proof.BatchNumberFinal),
 	)
 
+	// keep other aggregator replicas sharing the database from building a
+	// final proof for the same range
+	locked, err := a.tryLockFinalProofRange(ctx, proof.BatchNumber, proof.BatchNumberFinal)
+	if err != nil {
+		err = fmt.Errorf("failed to lock final proof batch range, %w", err)
+		return false, err
+	}
+	if !locked {
+		log.Info("Final proof for the batch range is being built by another aggregator")
+		// unlocks the proof ready to verify
+		err = errFinalProofRangeLocked
+		return false, nil
+	}
+
 	// at this point we have an eligible proof, build the final one using it
 	finalProof, err := a.buildFinalProof(ctx, prover, proof)
 	if err != nil {
+		a.releaseFinalProofRangeLock()
 		err = fmt.Errorf("failed to build final proof, %w", err)
 		log.Error(FirstToUpper(err.Error()))
 		return false, err
@@ -868,6 +1145,7 @@ func (a *Aggregator) tryBuildFinalProof(ctx context.Context, prover proverInterf
 
 	select {
 	case <-a.ctx.Done():
+		a.releaseFinalProofRangeLock()
 		return false, a.ctx.Err()
 	case a.finalProof <- msg:
 	}
@@ -971,11 +1249,19 @@ func (a *Aggregator) getAndLockProofsToAggregate(ctx context.Context, prover pro
 	a.stateDBMutex.Lock()
 	defer a.stateDBMutex.Unlock()
 
-	proof1, proof2, err := a.state.GetProofsToAggregate(ctx, nil)
+	proof1, proof2, err := a.getProofsToAggregate(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	compatible, err := a.checkProofsVersions(ctx, prover, proof1, proof2)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !compatible {
+		return nil, nil, state.ErrNotFound
+	}
+
 	// Set proofs in generating state in a single transaction
 	dbTx, err := a.state.BeginStateTransaction(ctx)
 	if err != nil {
@@ -1011,6 +1297,7 @@ func (a *Aggregator) getAndLockProofsToAggregate(ctx context.Context, prover pro
 func (a *Aggregator) tryAggregateProofs(ctx context.Context, prover proverInterface) (bool, error) {
 	proverName := prover.Name()
 	proverID := prover.ID()
+	proverVersion := prover.Version()
 
 	log := log.WithFields(
 		"prover", proverName,
@@ -1019,6 +1306,11 @@ func (a *Aggregator) tryAggregateProofs(ctx context.Context, prover proverInterf
 	)
 	log.Debug("tryAggregateProofs start")
 
+	if !a.proverAllowsJob(prover, aggregatedProofJob) {
+		log.Debug("Prover labels do not allow aggregations")
+		return false, nil
+	}
+
 	proof1, proof2, err0 := a.getAndLockProofsToAggregate(ctx, prover)
 	if errors.Is(err0, state.ErrNotFound) {
 		// nothing to aggregate, swallow the error
@@ -1065,11 +1357,17 @@ func (a *Aggregator) tryAggregateProofs(ctx context.Context, prover proverInterf
 		BatchNumberFinal: proof2.BatchNumberFinal,
 		Prover:           &proverName,
 		ProverID:         &proverID,
+		ProverVersion:    &proverVersion,
 		InputProver:      string(b),
 	}
 
+	untrackJob := a.trackProverJob(prover, aggregatedProofJob, proof.BatchNumberFinal, nil)
+	defer untrackJob()
+
+	provingStart := time.Now()
 	aggrProofID, err = prover.AggregatedProof(proof1.Proof, proof2.Proof)
 	if err != nil {
+		a.captureFailedProof(metrics.AggregationProofLevel(proof.BatchNumberFinal-proof.BatchNumber+1), proof, prover, proof.InputProver, err)
 		err = fmt.Errorf("failed to get aggregated proof id, %w", err)
 		log.Error(FirstToUpper(err.Error()))
 		return false, err
@@ -1081,11 +1379,16 @@ func (a *Aggregator) tryAggregateProofs(ctx context.Context, prover proverInterf
 	log = log.WithFields("proofId", *proof.ProofID)
 
 	recursiveProof, _, err := prover.WaitRecursiveProof(ctx, *proof.ProofID)
+	untrackJob()
 	if err != nil {
+		a.captureFailedProof(metrics.AggregationProofLevel(proof.BatchNumberFinal-proof.BatchNumber+1), proof, prover, proof.InputProver, err)
+		a.splitAggregationOnResourceExhausted(proof, err)
 		err = fmt.Errorf("failed to get aggregated proof from prover, %w", err)
 		log.Error(FirstToUpper(err.Error()))
 		return false, err
 	}
+	metrics.ProofGenerated(metrics.AggregationProofLevel(proof.BatchNumberFinal-proof.BatchNumber+1), time.Since(provingStart))
+	a.provingTimes.observe(aggregatedProofJob, time.Since(provingStart))
 
 	log.Info("Aggregated proof generated")
 
@@ -1174,6 +1477,7 @@ func (a *Aggregator) getVerifiedBatchAccInputHash(ctx context.Context, batchNumb
 func (a *Aggregator) getAndLockBatchToProve(ctx context.Context, prover proverInterface) (*state.Batch, *state.Proof, error) {
 	proverID := prover.ID()
 	proverName := prover.Name()
+	proverVersion := prover.Version()
 
 	log := log.WithFields(
 		"prover", proverName,
@@ -1203,8 +1507,24 @@ func (a *Aggregator) getAndLockBatchToProve(ctx context.Context, prover proverIn
 		}
 	}
 
+	if !a.proverCanRetryBatch(prover, batchNumberToVerify, lastVerifiedBatchNumber) {
+		log.Infof("Leaving batch %d to a prover out of the failure domain where its last proof failed", batchNumberToVerify)
+		return nil, nil, state.ErrNotFound
+	}
+
+	if a.cfg.StarvationGuard.Enabled {
+		limit, starving, err := a.aggregationStarvationLimit(ctx, lastVerifiedBatchNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		if starving && batchNumberToVerify > limit {
+			log.Infof("Holding back batch %d proof, aggregations up to batch %d are starving", batchNumberToVerify, limit)
+			return nil, nil, state.ErrNotFound
+		}
+	}
+
 	// Check if the batch has been sequenced
-	sequence, err := a.l1Syncr.GetSequenceByBatchNumber(ctx, batchNumberToVerify)
+	sequence, err := a.getSequenceByBatchNumber(ctx, batchNumberToVerify)
 	if err != nil && !errors.Is(err, entities.ErrNotFound) {
 		return nil, nil, err
 	}
@@ -1215,22 +1535,72 @@ func (a *Aggregator) getAndLockBatchToProve(ctx context.Context, prover proverIn
 		return nil, nil, state.ErrNotFound
 	}
 
+	mature, reason, err := a.isSequenceMature(ctx, sequence)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !mature {
+		log.Infof("Holding back batch %d proof, %s", batchNumberToVerify, reason)
+		return nil, nil, state.ErrNotFound
+	}
+
+	// Lock the sequence, so aggregator replicas sharing the database do not
+	// pick the same batch. The lock is released when dbTx ends.
+	dbTx, err := a.state.BeginStateTransaction(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			releaseBatchRangeLock(dbTx)
+		}
+	}()
+
+	err = a.state.LockBatchRange(ctx, sequenceLockNamespace, sequence.FromBatchNumber, sequence.ToBatchNumber, dbTx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// another replica may have picked the batch while waiting for the lock
+	proofExists, err = a.state.CheckProofExistsForBatch(ctx, batchNumberToVerify, dbTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if proofExists {
+		log.Debugf("Batch %d picked by another aggregator", batchNumberToVerify)
+		return nil, nil, state.ErrNotFound
+	}
+
 	stateSequence := state.Sequence{
 		FromBatchNumber: sequence.FromBatchNumber,
 		ToBatchNumber:   sequence.ToBatchNumber,
 	}
 
-	err = a.state.AddSequence(ctx, stateSequence, nil)
+	err = a.state.AddSequence(ctx, stateSequence, dbTx)
 	if err != nil {
 		log.Infof("Error storing sequence for batch %d", batchNumberToVerify)
 		return nil, nil, err
 	}
 
 	batch, _, err := a.state.GetBatch(ctx, batchNumberToVerify, nil)
+	if errors.Is(err, state.ErrNotFound) && a.cfg.BatchDataFallbackToL1 {
+		batch, err = a.buildBatchFromL1(ctx, batchNumberToVerify)
+	}
 	if err != nil {
 		return batch, nil, err
 	}
 
+	// the batches reconstructed from L1 are always checked, their roots are
+	// taken from the prover outputs without any other cross-check
+	if a.cfg.AccInputHashCheckEnabled || !batch.HasRoots() {
+		err = a.verifyAccInputHash(ctx, batch, sequence)
+		if err != nil {
+			log.Error(FirstToUpper(err.Error()))
+			return nil, nil, err
+		}
+	}
+
 	// All the data required to generate a proof is ready
 	log.Infof("Found virtual batch %d pending to generate proof", batch.BatchNumber)
 	log = log.WithFields("batch", batch.BatchNumber)
@@ -1255,16 +1625,24 @@ func (a *Aggregator) getAndLockBatchToProve(ctx context.Context, prover proverIn
 		BatchNumberFinal: batch.BatchNumber,
 		Prover:           &proverName,
 		ProverID:         &proverID,
+		ProverVersion:    &proverVersion,
 		GeneratingSince:  &now,
 	}
 
 	// Avoid other prover to process the same batch
-	err = a.state.AddGeneratedProof(ctx, proof, nil)
+	err = a.state.AddGeneratedProof(ctx, proof, dbTx)
 	if err != nil {
 		log.Errorf("Failed to add batch proof, err: %v", err)
 		return nil, nil, err
 	}
 
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		log.Errorf("Failed to commit batch proof, err: %v", err)
+		return nil, nil, err
+	}
+	committed = true
+
 	return batch, proof, nil
 }
 
@@ -1276,6 +1654,11 @@ func (a *Aggregator) tryGenerateBatchProof(ctx context.Context, prover proverInt
 	)
 	log.Debug("tryGenerateBatchProof start")
 
+	if !a.proverAllowsJob(prover, batchProofJob) {
+		log.Debug("Prover labels do not allow batch proofs")
+		return false, nil
+	}
+
 	batchToProve, proof, err0 := a.getAndLockBatchToProve(ctx, prover)
 	if errors.Is(err0, state.ErrNotFound) {
 		// nothing to proof, swallow the error
@@ -1289,12 +1672,16 @@ func (a *Aggregator) tryGenerateBatchProof(ctx context.Context, prover proverInt
 	log = log.WithFields("batch", batchToProve.BatchNumber)
 
 	var (
-		genProofID *string
-		err        error
+		genProofID   *string
+		err          error
+		sentToProver bool
 	)
 
 	defer func() {
 		if err != nil {
+			if sentToProver && !errors.Is(err, ErrProofPreempted) {
+				a.recordBatchProofFailure(batchToProve.BatchNumber, prover)
+			}
 			log.Debug("Deleting proof in progress")
 			err2 := a.state.DeleteGeneratedProofs(a.ctx, proof.BatchNumber, proof.BatchNumberFinal, nil)
 			if err2 != nil {
@@ -1315,8 +1702,14 @@ func (a *Aggregator) tryGenerateBatchProof(ctx context.Context, prover proverInt
 	log.Infof("Sending a batch to the prover. OldAccInputHash [%#x], L1InfoRoot [%#x]",
 		inputProver.PublicInputs.OldAccInputHash, inputProver.PublicInputs.L1InfoRoot)
 
+	jobCtx, endJob := a.startPreemptibleBatchProof(ctx, prover, batchToProve.BatchNumber)
+	defer endJob()
+
+	provingStart := time.Now()
+	sentToProver = true
 	genProofID, err = prover.BatchProof(inputProver)
 	if err != nil {
+		a.captureFailedProof(metrics.BatchProofLevel, proof, prover, inputProver, err)
 		err = fmt.Errorf("failed to get batch proof id, %w", err)
 		log.Error(FirstToUpper(err.Error()))
 		return false, err
@@ -1326,17 +1719,30 @@ func (a *Aggregator) tryGenerateBatchProof(ctx context.Context, prover proverInt
 
 	log = log.WithFields("proofId", *proof.ProofID)
 
-	resGetProof, stateRoot, err := prover.WaitRecursiveProof(ctx, *proof.ProofID)
+	resGetProof, stateRoot, err := prover.WaitRecursiveProof(jobCtx, *proof.ProofID)
+	if preempted := endJob(); preempted && err != nil {
+		a.cancelPreemptedProof(prover, *proof.ProofID)
+		err = fmt.Errorf("failed to get proof from prover, %w: %v", ErrProofPreempted, err)
+		log.Warn(FirstToUpper(err.Error()))
+		return false, err
+	}
 	if err != nil {
+		a.captureFailedProof(metrics.BatchProofLevel, proof, prover, inputProver, err)
 		err = fmt.Errorf("failed to get proof from prover, %w", err)
 		log.Error(FirstToUpper(err.Error()))
 		return false, err
 	}
+	provingTime := time.Since(provingStart)
+	metrics.ProofGenerated(metrics.BatchProofLevel, provingTime)
+	a.provingTimes.observe(batchProofJob, provingTime)
+	a.recordBatchStats(batchToProve, inputProver, provingTime, prover.Name())
+	a.clearBatchProofFailure(batchToProve.BatchNumber)
 
 	log.Info("Batch proof generated")
 
-	// Sanity Check: state root from the proof must match the one from the batch
-	if a.cfg.BatchProofSanityCheckEnabled && (stateRoot != common.Hash{}) && (stateRoot != batchToProve.StateRoot) {
+	// Sanity Check: state root from the proof must match the one from the batch.
+	// Batches reconstructed from L1 do not have a state root to compare with.
+	if a.cfg.BatchProofSanityCheckEnabled && batchToProve.HasRoots() && (stateRoot != common.Hash{}) && (stateRoot != batchToProve.StateRoot) {
 		log.Fatalf("State root from the proof does not match the expected for batch %d: Proof = [%s] Expected = [%s]", batchToProve.BatchNumber, stateRoot.String(), batchToProve.StateRoot.String())
 	}
 
@@ -1368,6 +1774,25 @@ func (a *Aggregator) tryGenerateBatchProof(ctx context.Context, prover proverInt
 	return true, nil
 }
 
+// setBuiltFinalProof keeps track of the last final proof built.
+func (a *Aggregator) setBuiltFinalProof(proofID string, finalProof *prover.FinalProof) {
+	a.builtFinalProofMutex.Lock()
+	defer a.builtFinalProofMutex.Unlock()
+	a.builtFinalProofID = proofID
+	a.builtFinalProof = finalProof
+}
+
+// getBuiltFinalProof returns the last final proof built if it matches the
+// given proof id, nil otherwise.
+func (a *Aggregator) getBuiltFinalProof(proofID string) *prover.FinalProof {
+	a.builtFinalProofMutex.RLock()
+	defer a.builtFinalProofMutex.RUnlock()
+	if a.builtFinalProofID != proofID {
+		return nil
+	}
+	return a.builtFinalProof
+}
+
 // canVerifyProof returns true if we have reached the timeout to verify a proof
 // and no other prover is verifying a proof (verifyingProof = false).
 func (a *Aggregator) canVerifyProof() bool {
@@ -1386,8 +1811,10 @@ func (a *Aggregator) startProofVerification() {
 // endProofVerification set verifyingProof to false to indicate that there is not proof verification in progress
 func (a *Aggregator) endProofVerification() {
 	a.timeSendFinalProofMutex.Lock()
-	defer a.timeSendFinalProofMutex.Unlock()
 	a.verifyingProof = false
+	a.timeSendFinalProofMutex.Unlock()
+	// the batch range can be built by other replicas if it was not verified
+	a.releaseFinalProofRangeLock()
 }
 
 // resetVerifyProofTime updates the timeout to verify a proof.
@@ -1492,7 +1919,7 @@ func (a *Aggregator) buildInputProver(ctx context.Context, batchToVerify *state.
 	}
 
 	// Get Witness
-	witness, err := getWitness(batchToVerify.BatchNumber, a.cfg.WitnessURL, a.cfg.UseFullWitness)
+	witness, err := getWitness(ctx, a.httpClient, batchToVerify.BatchNumber, a.cfg.WitnessURL, a.cfg.UseFullWitness)
 	if err != nil {
 		log.Errorf("Failed to get witness, err: %v", err)
 		return nil, err
@@ -1525,7 +1952,7 @@ func (a *Aggregator) buildInputProver(ctx context.Context, batchToVerify *state.
 	return inputProver, nil
 }
 
-func getWitness(batchNumber uint64, URL string, fullWitness bool) ([]byte, error) {
+func getWitness(ctx context.Context, client *http.Client, batchNumber uint64, URL string, fullWitness bool) ([]byte, error) {
 	var witness string
 	var response rpc.Response
 	var err error
@@ -1535,7 +1962,7 @@ func getWitness(batchNumber uint64, URL string, fullWitness bool) ([]byte, error
 		witnessType = "full"
 	}
 
-	response, err = rpc.JSONRPCCall(URL, "zkevm_getBatchWitness", batchNumber, witnessType)
+	response, err = jsonRPCCall(ctx, client, URL, "zkevm_getBatchWitness", batchNumber, witnessType)
 	if err != nil {
 		return nil, err
 	}
@@ -1603,6 +2030,7 @@ func (hc *healthChecker) Watch(req *grpchealth.HealthCheckRequest, server grpche
 
 func (a *Aggregator) handleMonitoredTxResult(result ethtxmanager.MonitoredTxResult) {
 	mTxResultLogger := ethtxmanager.CreateMonitoredTxResultLogger(result)
+	a.updateL1IntentFromResult(result)
 	if result.Status == ethtxmanager.MonitoredTxStatusFailed {
 		mTxResultLogger.Fatal("failed to send batch verification, TODO: review this fatal and define what to do in this case")
 	}
@@ -0,0 +1,56 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+const (
+	// AuditActionInjectFinalProof is the audit log action of the injection of
+	// an externally produced final proof
+	AuditActionInjectFinalProof = "inject_final_proof"
+
+	auditResultOK = "ok"
+)
+
+// adminPrincipalKey is the request context key of the authenticated operator
+type adminPrincipalKey struct{}
+
+// adminPrincipal returns the operator performing an admin API request. If the
+// request has not been authenticated, the remote address is used instead.
+func adminPrincipal(r *http.Request) string {
+	if principal, ok := r.Context().Value(adminPrincipalKey{}).(string); ok && principal != "" {
+		return principal
+	}
+	return "anonymous@" + r.RemoteAddr
+}
+
+// audit records an operator action in the audit log. A failure to record it
+// is logged but does not affect the action, which has already been performed.
+func (a *Aggregator) audit(r *http.Request, action string, params interface{}, result error) {
+	entry := &state.AuditLogEntry{
+		Action:    action,
+		Principal: adminPrincipal(r),
+		Result:    auditResultOK,
+	}
+	if result != nil {
+		entry.Result = result.Error()
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			log.Warnf("Failed to serialize audit log params of %s: %v", action, err)
+		} else {
+			entry.Params = data
+		}
+	}
+
+	if err := a.state.AddAuditLogEntry(a.ctx, entry, nil); err != nil {
+		log.Errorf("Failed to record %s by %s in the audit log: %v", action, entry.Principal, err)
+		return
+	}
+	log.Infof("Audit: %s by %s, result: %s", action, entry.Principal, entry.Result)
+}
@@ -0,0 +1,40 @@
+package aggregator
+
+import (
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// recordBatchStats stores the size and complexity of a proven batch along
+// with its proving time, to be correlated by the batch-stats command.
+func (a *Aggregator) recordBatchStats(batch *state.Batch, inputProver *prover.StatelessInputProver, provingTime time.Duration, proverName string) {
+	stats := &state.BatchStats{
+		BatchNumber: batch.BatchNumber,
+		WitnessSize: uint64(len(inputProver.PublicInputs.Witness)),
+		L2DataSize:  uint64(len(batch.BatchL2Data)),
+		ProvingTime: provingTime,
+		Prover:      proverName,
+	}
+
+	batchRaw, err := state.DecodeBatchV2(batch.BatchL2Data)
+	if err != nil {
+		log.Debugf("Failed to decode batch %d to record its stats: %v", batch.BatchNumber, err)
+	} else {
+		stats.BlockCount = uint64(len(batchRaw.Blocks))
+		for _, block := range batchRaw.Blocks {
+			stats.TxCount += uint64(len(block.Transactions))
+			for _, tx := range block.Transactions {
+				if tx.Tx != nil {
+					stats.GasLimit += tx.Tx.Gas()
+				}
+			}
+		}
+	}
+
+	if err := a.state.AddBatchStats(a.ctx, stats, nil); err != nil {
+		log.Warnf("Failed to store batch %d stats: %v", batch.BatchNumber, err)
+	}
+}
@@ -0,0 +1,84 @@
+package aggregator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// beneficiaryTemplateData is the data the beneficiary template is executed
+// with for each final proof submission.
+type beneficiaryTemplateData struct {
+	BatchNumber      uint64
+	BatchNumberFinal uint64
+	// Weekday is the UTC day of the week of the submission, 0 being Sunday
+	Weekday int
+	Time    time.Time
+	// Addresses are the configured beneficiary addresses
+	Addresses []string
+	Sender    string
+}
+
+var beneficiaryTemplateFuncs = template.FuncMap{
+	"mod": func(a uint64, b int) int {
+		if b <= 0 {
+			return 0
+		}
+		return int(a % uint64(b))
+	},
+}
+
+// newBeneficiaryTemplate parses the template used to choose the beneficiary
+// of each final proof submission, nil if none is configured.
+func newBeneficiaryTemplate(cfg BeneficiaryCfg) (*template.Template, error) {
+	if cfg.Template == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("beneficiary").Funcs(beneficiaryTemplateFuncs).Option("missingkey=error").Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid beneficiary template: %w", err)
+	}
+	for _, address := range cfg.Addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid beneficiary address %q", address)
+		}
+	}
+	return tmpl, nil
+}
+
+// beneficiary returns the address rewarded for verifying the proof, the
+// sender unless a beneficiary template is configured. If the template can not
+// be executed, the sender is used.
+func (a *Aggregator) beneficiary(proof *state.Proof) common.Address {
+	sender := common.HexToAddress(a.cfg.SenderAddress)
+	if a.beneficiaryTemplate == nil {
+		return sender
+	}
+
+	now := time.Now().UTC()
+	data := beneficiaryTemplateData{
+		BatchNumber:      proof.BatchNumber,
+		BatchNumberFinal: proof.BatchNumberFinal,
+		Weekday:          int(now.Weekday()),
+		Time:             now,
+		Addresses:        a.cfg.Beneficiary.Addresses,
+		Sender:           a.cfg.SenderAddress,
+	}
+	var buf bytes.Buffer
+	if err := a.beneficiaryTemplate.Execute(&buf, data); err != nil {
+		log.Errorf("Failed to execute beneficiary template for batches %d-%d, using the sender: %v", proof.BatchNumber, proof.BatchNumberFinal, err)
+		return sender
+	}
+	address := strings.TrimSpace(buf.String())
+	if !common.IsHexAddress(address) {
+		log.Errorf("Beneficiary template returned an invalid address %q for batches %d-%d, using the sender", address, proof.BatchNumber, proof.BatchNumberFinal)
+		return sender
+	}
+	return common.HexToAddress(address)
+}
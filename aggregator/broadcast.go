@@ -0,0 +1,100 @@
+package aggregator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/proxy"
+	"github.com/0xPolygonHermez/zkevm-ethtx-manager/ethtxmanager"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// l1Broadcaster is an additional L1 endpoint the verification txs are sent to
+type l1Broadcaster struct {
+	url    string
+	client *ethclient.Client
+}
+
+// newL1Broadcasters connects to the additional L1 endpoints. Endpoints that
+// can not be reached are skipped, they must not prevent the aggregator from
+// starting as the eth tx manager endpoint is enough to settle the proofs.
+func newL1Broadcasters(urls []string, proxyCfg proxy.Config) []*l1Broadcaster {
+	broadcasters := make([]*l1Broadcaster, 0, len(urls))
+	for _, url := range urls {
+		rpcClient, err := proxyCfg.DialRPC(context.Background(), url)
+		if err != nil {
+			log.Warnf("Failed to connect to L1 broadcast endpoint %s, skipping it: %v", url, err)
+			continue
+		}
+		broadcasters = append(broadcasters, &l1Broadcaster{url: url, client: ethclient.NewClient(rpcClient)})
+	}
+	return broadcasters
+}
+
+// broadcastMonitoredTx sends the signed txs of a monitored tx to the
+// additional L1 endpoints until ctx is done or the tx is mined. The eth tx
+// manager keeps monitoring the txs by hash, so it does not matter which
+// endpoint propagates the one that gets mined.
+func (a *Aggregator) broadcastMonitoredTx(ctx context.Context, monitoredTxID common.Hash) {
+	log := log.WithFields("monitoredTxId", monitoredTxID)
+	broadcasted := make(map[common.Hash]bool)
+
+	ticker := time.NewTicker(a.cfg.RetryTime.Duration)
+	defer ticker.Stop()
+
+	for {
+		result, err := a.ethTxManager.Result(ctx, monitoredTxID)
+		if err != nil {
+			log.Debugf("Failed to get monitored tx to broadcast: %v", err)
+		} else {
+			switch result.Status {
+			case ethtxmanager.MonitoredTxStatusMined, ethtxmanager.MonitoredTxStatusSafe,
+				ethtxmanager.MonitoredTxStatusFinalized, ethtxmanager.MonitoredTxStatusFailed:
+				return
+			}
+			for txHash, txResult := range result.Txs {
+				if broadcasted[txHash] || txResult.Tx == nil {
+					continue
+				}
+				broadcasted[txHash] = true
+				a.broadcastTx(ctx, txResult.Tx)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// broadcastTx sends a signed tx to all the additional L1 endpoints at once.
+func (a *Aggregator) broadcastTx(ctx context.Context, tx *types.Transaction) {
+	for _, broadcaster := range a.l1Broadcasters {
+		go func(broadcaster *l1Broadcaster) {
+			err := broadcaster.client.SendTransaction(ctx, tx)
+			switch {
+			case err == nil:
+				log.Infof("Tx %s broadcasted to %s", tx.Hash(), broadcaster.url)
+			case isKnownTxError(err):
+				log.Debugf("Tx %s already known by %s", tx.Hash(), broadcaster.url)
+			default:
+				log.Warnf("Failed to broadcast tx %s to %s: %v", tx.Hash(), broadcaster.url, err)
+			}
+		}(broadcaster)
+	}
+}
+
+// isKnownTxError returns true if the endpoint rejected the tx because it
+// already has it, or because it has already been mined.
+func isKnownTxError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "known transaction") ||
+		strings.Contains(msg, "nonce too low")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-aggregator/db"
 	"github.com/0xPolygonHermez/zkevm-aggregator/encoding"
 	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/proxy"
 	"github.com/0xPolygonHermez/zkevm-ethtx-manager/ethtxmanager"
 	syncronizerConfig "github.com/0xPolygonHermez/zkevm-synchronizer-l1/config"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
@@ -78,7 +79,13 @@ type Config struct {
 	// BatchProofSanityCheckEnabled is a flag to enable the sanity check of the batch proof
 	BatchProofSanityCheckEnabled bool `mapstructure:"BatchProofSanityCheckEnabled"`
 
-	// ChainID is the L2 ChainID provided by the Network Config
+	// AccInputHashCacheSize is the number of acc input hashes read from L1
+	// kept in memory. 0 disables the cache
+	AccInputHashCacheSize int `mapstructure:"AccInputHashCacheSize"`
+
+	// ChainID is the L2 ChainID read from the rollup contracts at startup. If
+	// set in the config, the aggregator refuses to start when the contracts
+	// report a different one
 	ChainID uint64
 
 	// ForkID is the L2 ForkID provided by the Network Config
@@ -88,6 +95,28 @@ type Config struct {
 	// to sign the L1 txs
 	SenderAddress string `mapstructure:"SenderAddress"`
 
+	// Beneficiary is the configuration of the address rewarded for each
+	// final proof, the sender by default
+	Beneficiary BeneficiaryCfg `mapstructure:"Beneficiary"`
+
+	// VerifierCheckInterval is the interval of time to check if the verifier of
+	// the rollup has been changed on L1. 0 disables the check
+	VerifierCheckInterval types.Duration `mapstructure:"VerifierCheckInterval"`
+
+	// BroadcastL1URLs are additional L1 endpoints the signed verification txs
+	// are sent to, besides the eth tx manager one, to maximize their propagation
+	BroadcastL1URLs []string `mapstructure:"BroadcastL1URLs"`
+
+	// VerificationTargets are secondary destinations, e.g. a mirror contract on
+	// another chain or an attestation service, the final proofs are sent to
+	// once verified by the settlement backend
+	VerificationTargets []VerificationTargetCfg `mapstructure:"VerificationTargets"`
+
+	// L1PermissionsCheckInterval is the interval of time to check that the sender
+	// still has the trusted aggregator role on the RollupManager. 0 disables the
+	// periodic check, the role is always checked at startup.
+	L1PermissionsCheckInterval types.Duration `mapstructure:"L1PermissionsCheckInterval"`
+
 	// CleanupLockedProofsInterval is the interval of time to clean up locked proofs.
 	CleanupLockedProofsInterval types.Duration `mapstructure:"CleanupLockedProofsInterval"`
 
@@ -117,6 +146,18 @@ type Config struct {
 	// UseFullWitness is a flag to enable the use of full witness in the aggregator
 	UseFullWitness bool `mapstructure:"UseFullWitness"`
 
+	// AccInputHashCheckEnabled is a flag to check the acc input hash computed from the
+	// local batch data against the one stored on L1 before proving the batch
+	AccInputHashCheckEnabled bool `mapstructure:"AccInputHashCheckEnabled"`
+
+	// ExitRootCheckEnabled is a flag to check the local exit root of the final
+	// proofs against the exit roots settled on L1 before submitting them
+	ExitRootCheckEnabled bool `mapstructure:"ExitRootCheckEnabled"`
+
+	// BatchDataFallbackToL1 is a flag to reconstruct the batches missing in the data stream
+	// from the sequencing data on L1, so proving can continue if the stream is behind or corrupt
+	BatchDataFallbackToL1 bool `mapstructure:"BatchDataFallbackToL1"`
+
 	// DB is the database configuration
 	DB db.Config `mapstructure:"DB"`
 
@@ -143,12 +184,226 @@ type Config struct {
 
 	// AggLayerURL url of the agglayer service
 	AggLayerURL string `mapstructure:"AggLayerURL"`
+
+	// AdminAPI is the configuration of the operator facing HTTP API
+	AdminAPI AdminAPICfg `mapstructure:"AdminAPI"`
+
+	// KeepWarm is the configuration of the jobs sent to idle provers
+	KeepWarm KeepWarmCfg `mapstructure:"KeepWarm"`
+
+	// Preemption is the configuration of the preemption of batch proofs in favour of the final proof
+	Preemption PreemptionCfg `mapstructure:"Preemption"`
+
+	// Provers is the configuration of the provers expected to connect
+	Provers ProversCfg `mapstructure:"Provers"`
+
+	// StarvationGuard is the configuration of the guard keeping aggregations from starving behind new batch proofs
+	StarvationGuard StarvationGuardCfg `mapstructure:"StarvationGuard"`
+
+	// ETA is the configuration of the estimation of the proving backlog ETA
+	ETA ETACfg `mapstructure:"ETA"`
+
+	// ProofPartitioning is the configuration of the maintenance of the batch range partitions of the proof table
+	ProofPartitioning ProofPartitioningCfg `mapstructure:"ProofPartitioning"`
+
+	// BatchMaturity is the configuration of how long a batch must have been sequenced on L1 before it is proven
+	BatchMaturity BatchMaturityCfg `mapstructure:"BatchMaturity"`
+
+	// FailedProofs is the configuration of the forensic capture of the proofs the provers fail to generate
+	FailedProofs FailedProofsCfg `mapstructure:"FailedProofs"`
+
+	// Sequences is the configuration of where the sequences of batches are read from
+	Sequences SequencesCfg `mapstructure:"Sequences"`
+
+	// Watchdog is the configuration of the detection and remediation of the stalls of the proving pipeline
+	Watchdog WatchdogCfg `mapstructure:"Watchdog"`
+
+	// ProverVersions is the configuration of the compatibility of the proofs generated by different prover versions
+	ProverVersions ProverVersionsCfg `mapstructure:"ProverVersions"`
+
+	// Proxy the outgoing connections of the aggregator go through, set from
+	// the top level Proxy configuration
+	Proxy proxy.Config `mapstructure:"-"`
+}
+
+// AdminAPICfg contains the admin HTTP API configuration properties
+type AdminAPICfg struct {
+	// Enabled is the flag to enable/disable the admin API server
+	Enabled bool `mapstructure:"Enabled"`
+	// Host is the address to bind the admin API server
+	Host string `mapstructure:"Host"`
+	// Port is the port to bind the admin API server
+	Port int `mapstructure:"Port"`
+	// APIKeys are the keys allowed to use the admin API. If empty, the admin
+	// API is not authenticated
+	APIKeys []AdminAPIKey `mapstructure:"APIKeys"`
+	// ExplorerL1URL is the URL of the L1 block explorer the verification txs
+	// returned by the explorer endpoints link to, e.g. https://etherscan.io
+	ExplorerL1URL string `mapstructure:"ExplorerL1URL"`
+}
+
+// AdminAPIKey is a key allowed to use the admin API with the given role
+type AdminAPIKey struct {
+	// Name identifies the holder of the key in the audit log
+	Name string `mapstructure:"Name"`
+	// Key is the secret sent in the X-API-Key header or as bearer token
+	Key string `mapstructure:"Key"`
+	// Role is one of viewer, operator or admin
+	Role AdminRole `mapstructure:"Role"`
+}
+
+// VerificationTargetCfg contains the configuration of a secondary verification
+// target
+type VerificationTargetCfg struct {
+	// Name identifies the target in the logs
+	Name string `mapstructure:"Name"`
+	// Type is http or evm
+	Type string `mapstructure:"Type"`
+	// URL is the HTTP endpoint the proof is posted to, or the RPC endpoint of
+	// the chain of the target contract
+	URL string `mapstructure:"URL"`
+	// ContractAddress is the address of the target contract, evm only
+	ContractAddress string `mapstructure:"ContractAddress"`
+	// PrivateKey is the key signing the txs sent to the target contract, evm only
+	PrivateKey types.KeystoreFileConfig `mapstructure:"PrivateKey"`
+	// Timeout is the maximum time of a submission attempt
+	Timeout types.Duration `mapstructure:"Timeout"`
+}
+
+// KeepWarmCfg contains the configuration of the keepwarm jobs. A keepwarm job
+// re-proves an already known batch on a prover that has been idle for too
+// long, keeping its GPU kernels warm and detecting silently broken provers
+// before a real batch is assigned to them.
+type KeepWarmCfg struct {
+	// Enabled is the flag to enable/disable the keepwarm jobs
+	Enabled bool `mapstructure:"Enabled"`
+	// IdleInterval is the time a prover must be idle before a keepwarm job is sent to it
+	IdleInterval types.Duration `mapstructure:"IdleInterval"`
+}
+
+// PreemptionCfg contains the configuration of the preemption of batch proofs.
+// When the time to send a final proof is reached and all the provers are busy,
+// the batch proof of the highest batch number is canceled and requeued so its
+// prover can build the final proof.
+type PreemptionCfg struct {
+	// Enabled is the flag to enable/disable the preemption of batch proofs
+	Enabled bool `mapstructure:"Enabled"`
+	// CheckInterval is the interval to check if a batch proof must be preempted
+	CheckInterval types.Duration `mapstructure:"CheckInterval"`
+}
+
+// ProversCfg contains the configuration of the provers allowed to connect
+type ProversCfg struct {
+	// UnknownPolicy is applied to the provers not in Allowed when Allowed is
+	// not empty: accept, reject or quarantine
+	UnknownPolicy UnknownProverPolicy `mapstructure:"UnknownPolicy"`
+	// Allowed are the provers expected to connect
+	Allowed []ProverCfg `mapstructure:"Allowed"`
+	// SeparateRetryDomains gives the retry of a failed batch proof to a
+	// prover out of the failure domain of the prover that failed it, when one
+	// is connected
+	SeparateRetryDomains bool `mapstructure:"SeparateRetryDomains"`
+}
+
+// ProverCfg contains the configuration of an expected prover
+type ProverCfg struct {
+	// Name is the name reported by the prover
+	Name string `mapstructure:"Name"`
+	// ID is the id reported by the prover. If empty, any id matches
+	ID string `mapstructure:"ID"`
+	// Labels describe the prover. The batch, aggregation and final labels
+	// restrict the jobs it is given to those kinds
+	Labels []string `mapstructure:"Labels"`
+	// MaxConcurrentJobs is the maximum number of jobs given at once to the
+	// provers with this name. 0 means no limit
+	MaxConcurrentJobs int `mapstructure:"MaxConcurrentJobs"`
+	// FailureDomain is the rack or zone of the prover, provers in the same
+	// domain are expected to fail together
+	FailureDomain string `mapstructure:"FailureDomain"`
+}
+
+// StarvationGuardCfg contains the configuration of the aggregation starvation
+// guard. When a proof has been waiting to be aggregated for too long, no batch
+// proof beyond the batches it needs to be aggregated is started, until it is.
+type StarvationGuardCfg struct {
+	// Enabled is the flag to enable/disable the aggregation starvation guard
+	Enabled bool `mapstructure:"Enabled"`
+	// MaxAggregationWait is the time a proof can wait to be aggregated before new batch proofs are held back
+	MaxAggregationWait types.Duration `mapstructure:"MaxAggregationWait"`
+}
+
+// BeneficiaryCfg contains the configuration of the beneficiary of the final
+// proofs
+type BeneficiaryCfg struct {
+	// Template is a Go template returning the beneficiary address of each
+	// final proof submission. It is executed with .BatchNumber,
+	// .BatchNumberFinal, .Weekday (0 is Sunday, UTC), .Time, .Addresses and
+	// .Sender, and the mod function, e.g. to rotate by batch range:
+	// {{ index .Addresses (mod .BatchNumberFinal (len .Addresses)) }}
+	// If empty, the sender is the beneficiary
+	Template string `mapstructure:"Template"`
+	// Addresses are the beneficiary addresses the template can choose from
+	Addresses []string `mapstructure:"Addresses"`
+}
+
+// ETACfg contains the configuration of the estimation of the time needed to
+// prove the batches not proven yet
+type ETACfg struct {
+	// Window is the number of latest proving times of each kind of proof the estimations are averaged from
+	Window int `mapstructure:"Window"`
+	// UpdateInterval is the interval to update the ETA metrics, 0 disables them
+	UpdateInterval types.Duration `mapstructure:"UpdateInterval"`
+}
+
+// FailedProofsCfg contains the configuration of the forensic capture of the
+// proofs the provers fail to generate or return malformed
+type FailedProofsCfg struct {
+	// CaptureEnabled is the flag to store the inputs and error of the failed proofs
+	CaptureEnabled bool `mapstructure:"CaptureEnabled"`
+	// ExportURL is the object storage URL the failed proofs are uploaded to
+	// with a PUT request, as <ExportURL>/<id>.json. If empty, nothing is exported
+	ExportURL string `mapstructure:"ExportURL"`
+	// ExportAuthorization is the value of the Authorization header of the
+	// export requests, if any
+	ExportAuthorization string `mapstructure:"ExportAuthorization"`
+	// ExportTimeout is the timeout of the export requests
+	ExportTimeout types.Duration `mapstructure:"ExportTimeout"`
+}
+
+// ProofPartitioningCfg contains the configuration of the batch range
+// partitions of the proof table. Proofs out of the created partitions are
+// stored in the default partition.
+type ProofPartitioningCfg struct {
+	// Enabled is the flag to create and drop the partitions
+	Enabled bool `mapstructure:"Enabled"`
+	// BatchRange is the number of batches of each partition
+	BatchRange uint64 `mapstructure:"BatchRange"`
+	// Ahead is the number of partitions created beyond the one of the first
+	// unverified batch
+	Ahead uint64 `mapstructure:"Ahead"`
+	// CheckInterval is the interval to create and drop the partitions
+	CheckInterval types.Duration `mapstructure:"CheckInterval"`
+}
+
+// BatchMaturityCfg contains the minimum age of the sequence of a batch before
+// the batch is proven, to avoid wasting proofs on batches the sequencer may
+// sequence again after an L1 reorg
+type BatchMaturityCfg struct {
+	// MinAge is the time the sequence must have been on L1, measured from the
+	// timestamp of its L1 block. 0 disables the check
+	MinAge types.Duration `mapstructure:"MinAge"`
+	// MinL1Confirmations is the number of L1 blocks that must have been mined
+	// on top of the block of the sequence. 0 disables the check
+	MinL1Confirmations uint64 `mapstructure:"MinL1Confirmations"`
 }
 
 // StreamClientCfg contains the data streamer's configuration properties
 type StreamClientCfg struct {
 	// Datastream server to connect
 	Server string `mapstructure:"Server"`
+	// Protocol is the data stream protocol version: 1 for the legacy one, 2
+	// for the protobuf one or 0 to negotiate it from the stream header
+	Protocol uint8 `mapstructure:"Protocol"`
 	// Log is the log configuration
 	Log log.Config `mapstructure:"Log"`
 }
@@ -168,3 +423,49 @@ func newKeyFromKeystore(cfg types.KeystoreFileConfig) (*ecdsa.PrivateKey, error)
 	}
 	return key.PrivateKey, nil
 }
+
+// SequencesCfg contains the configuration of the source of the sequences of
+// batches the aggregator proves
+type SequencesCfg struct {
+	// Source is where the sequences are read from: synchronizer, the
+	// sequences decoded by the L1 synchronizer, or rollupmanager, the
+	// OnSequenceBatches events of the RollupManager, read from the genesis
+	// block of the synchronizer
+	Source SequenceSource `mapstructure:"Source"`
+	// CheckInterval is the interval to read the new sequences from the
+	// RollupManager
+	CheckInterval types.Duration `mapstructure:"CheckInterval"`
+}
+
+// WatchdogCfg contains the configuration of the watchdog of the proving
+// pipeline. The pipeline is stalled when neither the last verified batch nor
+// the proofs change for StallTimeout while there are batches to prove.
+type WatchdogCfg struct {
+	// Enabled is the flag to enable/disable the watchdog
+	Enabled bool `mapstructure:"Enabled"`
+	// CheckInterval is the interval to check the pipeline
+	CheckInterval types.Duration `mapstructure:"CheckInterval"`
+	// StallTimeout is the time without changes after which the pipeline is
+	// stalled
+	StallTimeout types.Duration `mapstructure:"StallTimeout"`
+	// DiagnosticsDir is the directory the goroutine dump and the proofs in
+	// progress are written to on stalls. If empty, no diagnostics are written
+	DiagnosticsDir string `mapstructure:"DiagnosticsDir"`
+	// ResetGeneratingProofs deletes the proofs in progress on stalls, so they
+	// are generated again
+	ResetGeneratingProofs bool `mapstructure:"ResetGeneratingProofs"`
+	// ReconnectProvers closes the channels of the connected provers on
+	// stalls, so they reconnect
+	ReconnectProvers bool `mapstructure:"ReconnectProvers"`
+}
+
+// ProverVersionsCfg contains the compatibility matrix of the prover versions.
+// Proofs generated by incompatible versions are not aggregated together, as
+// the resulting final proof would fail the verifier on L1.
+type ProverVersionsCfg struct {
+	// Enabled is the flag to enable/disable the compatibility guard
+	Enabled bool `mapstructure:"Enabled"`
+	// Compatible are groups of server versions whose proofs can be
+	// aggregated together. A version is always compatible with itself
+	Compatible [][]string `mapstructure:"Compatible"`
+}
@@ -0,0 +1,163 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// ErrNoProvingTimes is returned when there are no proving times to estimate
+// the ETAs from.
+var ErrNoProvingTimes = errors.New("no proving times recorded yet")
+
+// provingTimes keeps a moving window of the latest proving times of each
+// kind of job.
+type provingTimes struct {
+	window  int
+	samples map[proverJobKind][]time.Duration
+	mutex   sync.RWMutex
+}
+
+func newProvingTimes(window int) *provingTimes {
+	return &provingTimes{
+		window:  window,
+		samples: make(map[proverJobKind][]time.Duration),
+	}
+}
+
+// observe adds a proving time to the window of its kind of job.
+func (t *provingTimes) observe(kind proverJobKind, elapsed time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	samples := append(t.samples[kind], elapsed)
+	if len(samples) > t.window {
+		samples = samples[len(samples)-t.window:]
+	}
+	t.samples[kind] = samples
+}
+
+// average returns the moving average of the proving times of the kind of job,
+// or 0 if none has been observed.
+func (t *provingTimes) average(kind proverJobKind) time.Duration {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	samples := t.samples[kind]
+	if len(samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	return total / time.Duration(len(samples))
+}
+
+// BacklogETA is the estimated time for the unproven batches to be proven.
+type BacklogETA struct {
+	LastVerifiedBatchNumber uint64 `json:"lastVerifiedBatchNumber"`
+	UnprovenBatches         int    `json:"unprovenBatches"`
+	Provers                 int64  `json:"provers"`
+	// average proving times the estimation is based on
+	BatchProofTime       time.Duration `json:"batchProofTime"`
+	AggregationProofTime time.Duration `json:"aggregationProofTime"`
+	FinalProofTime       time.Duration `json:"finalProofTime"`
+	// ClearTime is the estimated time to prove all the unproven batches up to
+	// the final proof
+	ClearTime time.Duration `json:"clearTime"`
+	ClearAt   time.Time     `json:"clearAt"`
+	// BatchETAs is the estimated time each unproven batch has been proven at
+	BatchETAs map[uint64]time.Time `json:"batchEtas,omitempty"`
+}
+
+// seedProvingTimes loads the latest batch proving times recorded in the batch
+// stats, so the estimations are available right after a restart.
+func (a *Aggregator) seedProvingTimes(ctx context.Context, lastVerifiedBatchNumber uint64) {
+	var fromBatchNumber uint64
+	if lastVerifiedBatchNumber > uint64(a.cfg.ETA.Window) {
+		fromBatchNumber = lastVerifiedBatchNumber - uint64(a.cfg.ETA.Window)
+	}
+	stats, err := a.state.GetBatchStats(ctx, fromBatchNumber, math.MaxInt64, nil)
+	if err != nil {
+		log.Warnf("Failed to load the batch proving times to seed the ETA estimations: %v", err)
+		return
+	}
+	for _, s := range stats {
+		a.provingTimes.observe(batchProofJob, s.ProvingTime)
+	}
+}
+
+// estimateBacklog estimates when the batches not proven yet will be proven,
+// assuming the connected provers work on them in parallel and the batch
+// proofs are aggregated in a balanced binary tree.
+func (a *Aggregator) estimateBacklog(ctx context.Context) (*BacklogETA, error) {
+	batchProofTime := a.provingTimes.average(batchProofJob)
+	if batchProofTime == 0 {
+		return nil, ErrNoProvingTimes
+	}
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		return nil, err
+	}
+	unproven, err := a.state.GetUnprovenBatchNumbers(ctx, lastVerifiedBatchNumber, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	provers := a.connectedProvers.Load()
+	if provers < 1 {
+		provers = 1
+	}
+
+	now := time.Now()
+	eta := &BacklogETA{
+		LastVerifiedBatchNumber: lastVerifiedBatchNumber,
+		UnprovenBatches:         len(unproven),
+		Provers:                 provers,
+		BatchProofTime:          batchProofTime,
+		AggregationProofTime:    a.provingTimes.average(aggregatedProofJob),
+		FinalProofTime:          a.provingTimes.average(finalProofJob),
+		BatchETAs:               make(map[uint64]time.Time, len(unproven)),
+	}
+	for i, batchNumber := range unproven {
+		rounds := int64(i)/provers + 1
+		eta.BatchETAs[batchNumber] = now.Add(time.Duration(rounds) * batchProofTime)
+	}
+	if len(unproven) > 0 {
+		rounds := (int64(len(unproven)) + provers - 1) / provers
+		depth := bits.Len64(uint64(len(unproven)) - 1)
+		eta.ClearTime = time.Duration(rounds)*batchProofTime + time.Duration(depth)*eta.AggregationProofTime + eta.FinalProofTime
+	}
+	eta.ClearAt = now.Add(eta.ClearTime)
+
+	return eta, nil
+}
+
+// updateETAMetrics periodically exports the backlog estimations as metrics.
+func (a *Aggregator) updateETAMetrics() {
+	ticker := time.NewTicker(a.cfg.ETA.UpdateInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			eta, err := a.estimateBacklog(a.ctx)
+			if errors.Is(err, ErrNoProvingTimes) {
+				continue
+			} else if err != nil {
+				log.Warnf("Failed to estimate the proving backlog: %v", err)
+				continue
+			}
+			metrics.BacklogEstimated(eta.UnprovenBatches, eta.BatchProofTime, eta.ClearTime)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrExitRootConflict is returned when the local exit root of a final proof
+// conflicts with the exits already settled on L1.
+var ErrExitRootConflict = errors.New("local exit root conflicts with the settled exits")
+
+// validateExitRoots checks the new local exit root of a final proof against
+// the exit roots settled on L1 before submitting it. The local exit root is
+// the root of an append-only tree, so once exits are settled it can never go
+// back to the empty root, and the settled root must be the one this
+// aggregator computed for the last verified batch, otherwise the proof builds
+// on a different history of exits.
+func (a *Aggregator) validateExitRoots(ctx context.Context, proof *state.Proof, inputs *ethmanTypes.FinalProofInputs) error {
+	roots, err := a.etherman.GetExitRoots(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the exit roots settled on L1: %w", err)
+	}
+	newLocalExitRoot := common.BytesToHash(inputs.NewLocalExitRoot)
+
+	if roots.RollupExitRoot != roots.GlobalRollupExitRoot {
+		log.Warnf("Rollup exit root %s of the RollupManager is not the one of the GlobalExitRootManager %s",
+			roots.RollupExitRoot, roots.GlobalRollupExitRoot)
+	}
+
+	if newLocalExitRoot == (common.Hash{}) && roots.LastLocalExitRoot != (common.Hash{}) {
+		return fmt.Errorf("%w: batches %d-%d would reset the local exit root %s settled at batch %d",
+			ErrExitRootConflict, proof.BatchNumber, proof.BatchNumberFinal, roots.LastLocalExitRoot, roots.LastVerifiedBatch)
+	}
+
+	if roots.LastVerifiedBatch+1 == proof.BatchNumber {
+		settledBatch, _, err := a.state.GetBatch(ctx, roots.LastVerifiedBatch, nil)
+		switch {
+		case errors.Is(err, state.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to get batch %d: %w", roots.LastVerifiedBatch, err)
+		// batches only known by their acc input hash or reconstructed from
+		// L1 do not have roots
+		case settledBatch.HasRoots() && settledBatch.StateRoot != (common.Hash{}) && settledBatch.LocalExitRoot != roots.LastLocalExitRoot:
+			return fmt.Errorf("%w: local exit root of batch %d is %s locally but %s on L1",
+				ErrExitRootConflict, roots.LastVerifiedBatch, settledBatch.LocalExitRoot, roots.LastLocalExitRoot)
+		}
+	}
+
+	if newLocalExitRoot != roots.LastLocalExitRoot {
+		log.Infof("Batches %d-%d move the local exit root from %s to %s",
+			proof.BatchNumber, proof.BatchNumberFinal, roots.LastLocalExitRoot, newLocalExitRoot)
+	}
+	return nil
+}
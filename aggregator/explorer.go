@@ -0,0 +1,285 @@
+package aggregator
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	xlayermetrics "github.com/0xPolygonHermez/zkevm-aggregator/xlayer/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// ExplorerBatchesEndpoint is the explorer endpoint to browse the batches
+	ExplorerBatchesEndpoint = "/explorer/batches"
+	// ExplorerProofsEndpoint is the explorer endpoint to browse the proofs
+	ExplorerProofsEndpoint = "/explorer/proofs"
+	// ExplorerSequencesEndpoint is the explorer endpoint to browse the sequences
+	ExplorerSequencesEndpoint = "/explorer/sequences"
+	// ExplorerVerificationsEndpoint is the explorer endpoint to browse the
+	// verification txs sent to L1
+	ExplorerVerificationsEndpoint = "/explorer/verifications"
+)
+
+// ExplorerPage is a page of the items returned by the explorer endpoints.
+// Next is the from parameter of the next page, absent on the last one.
+type ExplorerPage struct {
+	Items interface{} `json:"items"`
+	Next  *uint64     `json:"next,omitempty"`
+}
+
+// ExplorerBatch is a batch as returned by the explorer endpoints, without
+// its data
+type ExplorerBatch struct {
+	BatchNumber    uint64         `json:"batchNumber"`
+	Coinbase       common.Address `json:"coinbase"`
+	StateRoot      common.Hash    `json:"stateRoot"`
+	LocalExitRoot  common.Hash    `json:"localExitRoot"`
+	AccInputHash   common.Hash    `json:"accInputHash"`
+	L1InfoRoot     common.Hash    `json:"l1InfoRoot"`
+	GlobalExitRoot common.Hash    `json:"globalExitRoot"`
+	Timestamp      time.Time      `json:"timestamp"`
+	ForkID         uint64         `json:"forkId"`
+	BatchL2DataLen int            `json:"batchL2DataLength"`
+}
+
+// ExplorerProof is a proof as returned by the explorer endpoints, linking to
+// its blob instead of including it
+type ExplorerProof struct {
+	BatchNumber      uint64     `json:"batchNumber"`
+	BatchNumberFinal uint64     `json:"batchNumberFinal"`
+	ProofID          *string    `json:"proofId,omitempty"`
+	Prover           *string    `json:"prover,omitempty"`
+	ProverID         *string    `json:"proverId,omitempty"`
+	GeneratingSince  *time.Time `json:"generatingSince,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+	BlobURL          string     `json:"blobUrl,omitempty"`
+}
+
+// ExplorerSequence is a sequence as returned by the explorer endpoints
+type ExplorerSequence struct {
+	FromBatchNumber uint64 `json:"fromBatchNumber"`
+	ToBatchNumber   uint64 `json:"toBatchNumber"`
+}
+
+// ExplorerVerification is a verification tx as returned by the explorer
+// endpoints. TxHash and TxURL are only set once the tx has been mined.
+type ExplorerVerification struct {
+	BatchNumber      uint64               `json:"batchNumber"`
+	BatchNumberFinal uint64               `json:"batchNumberFinal"`
+	Nonce            uint64               `json:"nonce"`
+	Status           state.L1IntentStatus `json:"status"`
+	MonitoredTxID    *common.Hash         `json:"monitoredTxId,omitempty"`
+	TxHash           *common.Hash         `json:"txHash,omitempty"`
+	TxURL            string               `json:"txUrl,omitempty"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	UpdatedAt        time.Time            `json:"updatedAt"`
+}
+
+// explorerPageParams parses the from and limit query parameters, writing the
+// error response and returning false if they are not valid.
+func explorerPageParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	limit, ok := adminLimit(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	var from uint64
+	if value := r.URL.Query().Get("from"); value != "" {
+		var err error
+		from, err = strconv.ParseUint(value, 10, 64) //nolint:gomnd
+		if err != nil {
+			http.Error(w, "invalid from batch number", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	return from, limit, true
+}
+
+// explorerNext returns the from parameter of the page after the one ending at
+// the given batch, nil if the page is not full.
+func explorerNext(items int, limit uint64, lastBatchNumber uint64) *uint64 {
+	if uint64(items) < limit {
+		return nil
+	}
+	next := lastBatchNumber + 1
+	return &next
+}
+
+// handleExplorerBatches returns a page of batches from the given one.
+//
+//	GET /explorer/batches?from={batchNumber}&limit={limit}
+func (a *Aggregator) handleExplorerBatches(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	from, limit, ok := explorerPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	batches, err := a.state.GetBatches(r.Context(), from, limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get batches: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]ExplorerBatch, 0, len(batches))
+	for _, batch := range batches {
+		items = append(items, ExplorerBatch{
+			BatchNumber:    batch.BatchNumber,
+			Coinbase:       batch.Coinbase,
+			StateRoot:      batch.StateRoot,
+			LocalExitRoot:  batch.LocalExitRoot,
+			AccInputHash:   batch.AccInputHash,
+			L1InfoRoot:     batch.L1InfoRoot,
+			GlobalExitRoot: batch.GlobalExitRoot,
+			Timestamp:      batch.Timestamp,
+			ForkID:         batch.ForkID,
+			BatchL2DataLen: len(batch.BatchL2Data),
+		})
+	}
+	page := ExplorerPage{Items: items}
+	if len(items) > 0 {
+		page.Next = explorerNext(len(items), limit, items[len(items)-1].BatchNumber)
+	}
+	writeAdminJSON(w, page)
+}
+
+// handleExplorerProofs returns a page of the proofs ending at or after the
+// given batch.
+//
+//	GET /explorer/proofs?from={batchNumber}&limit={limit}
+func (a *Aggregator) handleExplorerProofs(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	from, limit, ok := explorerPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	proofs, err := a.state.GetProofs(r.Context(), from, limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get proofs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]ExplorerProof, 0, len(proofs))
+	for _, proof := range proofs {
+		item := ExplorerProof{
+			BatchNumber:      proof.BatchNumber,
+			BatchNumberFinal: proof.BatchNumberFinal,
+			ProofID:          proof.ProofID,
+			Prover:           proof.Prover,
+			ProverID:         proof.ProverID,
+			GeneratingSince:  proof.GeneratingSince,
+			CreatedAt:        proof.CreatedAt,
+			UpdatedAt:        proof.UpdatedAt,
+		}
+		if proof.ProofID != nil && proof.GeneratingSince == nil {
+			item.BlobURL = AdminProofsEndpoint + *proof.ProofID + adminProofBlobSuffix
+		}
+		items = append(items, item)
+	}
+	page := ExplorerPage{Items: items}
+	if len(items) > 0 {
+		page.Next = explorerNext(len(items), limit, items[len(items)-1].BatchNumberFinal)
+	}
+	writeAdminJSON(w, page)
+}
+
+// handleExplorerSequences returns a page of the sequences ending at or after
+// the given batch.
+//
+//	GET /explorer/sequences?from={batchNumber}&limit={limit}
+func (a *Aggregator) handleExplorerSequences(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	from, limit, ok := explorerPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	sequences, err := a.state.GetSequences(r.Context(), from, limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get sequences: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]ExplorerSequence, 0, len(sequences))
+	for _, sequence := range sequences {
+		items = append(items, ExplorerSequence(sequence))
+	}
+	page := ExplorerPage{Items: items}
+	if len(items) > 0 {
+		page.Next = explorerNext(len(items), limit, items[len(items)-1].ToBatchNumber)
+	}
+	writeAdminJSON(w, page)
+}
+
+// handleExplorerVerifications returns a page of the verification txs ending
+// at or after the given batch, linking the mined ones to the L1 explorer.
+//
+//	GET /explorer/verifications?from={batchNumber}&limit={limit}
+func (a *Aggregator) handleExplorerVerifications(w http.ResponseWriter, r *http.Request) {
+	xlayermetrics.CodePathHit(xlayermetrics.AdminAPICodePath)
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	from, limit, ok := explorerPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	intents, err := a.state.GetL1Intents(r.Context(), from, limit, nil)
+	if err != nil {
+		log.Errorf("Failed to get L1 intents: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]ExplorerVerification, 0, len(intents))
+	for _, intent := range intents {
+		item := ExplorerVerification{
+			BatchNumber:      intent.BatchNumber,
+			BatchNumberFinal: intent.BatchNumberFinal,
+			Nonce:            intent.Nonce,
+			Status:           intent.Status,
+			MonitoredTxID:    intent.MonitoredTxID,
+			CreatedAt:        intent.CreatedAt,
+			UpdatedAt:        intent.UpdatedAt,
+		}
+		if intent.MonitoredTxID != nil && intent.Status == state.L1IntentMined {
+			result, err := a.ethTxManager.Result(r.Context(), *intent.MonitoredTxID)
+			if err != nil {
+				log.Debugf("Failed to get the monitored tx %s: %v", intent.MonitoredTxID, err)
+			} else if txHash := minedTxHash(result); txHash != (common.Hash{}) {
+				item.TxHash = &txHash
+				if a.cfg.AdminAPI.ExplorerL1URL != "" {
+					item.TxURL = strings.TrimSuffix(a.cfg.AdminAPI.ExplorerL1URL, "/") + "/tx/" + txHash.Hex()
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	page := ExplorerPage{Items: items}
+	if len(items) > 0 {
+		page.Next = explorerNext(len(items), limit, items[len(items)-1].BatchNumberFinal)
+	}
+	writeAdminJSON(w, page)
+}
@@ -0,0 +1,108 @@
+package aggregator
+
+import (
+	"sync"
+)
+
+// failureDomains keeps track of the failure domains of the connected provers
+// and of the domain where the last attempt to prove each batch failed, so the
+// retry is given to a prover in a different domain.
+type failureDomains struct {
+	mutex sync.Mutex
+	// connected provers by failure domain
+	connected map[string]int
+	// failure domain of the last failed proof, by batch number
+	failed map[uint64]string
+}
+
+func newFailureDomains() *failureDomains {
+	return &failureDomains{
+		connected: make(map[string]int),
+		failed:    make(map[uint64]string),
+	}
+}
+
+// proverFailureDomain returns the failure domain configured for the prover,
+// empty if it has none.
+func (a *Aggregator) proverFailureDomain(prover proverInterface) string {
+	cfg := a.proverCfg(prover)
+	if cfg == nil {
+		return ""
+	}
+	return cfg.FailureDomain
+}
+
+// connectProverDomain counts the prover as connected in its failure domain
+// until the returned function is called.
+func (a *Aggregator) connectProverDomain(prover proverInterface) func() {
+	domain := a.proverFailureDomain(prover)
+	if domain == "" {
+		return func() {}
+	}
+
+	a.failureDomains.mutex.Lock()
+	a.failureDomains.connected[domain]++
+	a.failureDomains.mutex.Unlock()
+
+	return func() {
+		a.failureDomains.mutex.Lock()
+		defer a.failureDomains.mutex.Unlock()
+		a.failureDomains.connected[domain]--
+		if a.failureDomains.connected[domain] <= 0 {
+			delete(a.failureDomains.connected, domain)
+		}
+	}
+}
+
+// recordBatchProofFailure remembers the failure domain of the prover that
+// failed to prove the batch.
+func (a *Aggregator) recordBatchProofFailure(batchNumber uint64, prover proverInterface) {
+	domain := a.proverFailureDomain(prover)
+	if !a.cfg.Provers.SeparateRetryDomains || domain == "" {
+		return
+	}
+
+	a.failureDomains.mutex.Lock()
+	defer a.failureDomains.mutex.Unlock()
+	a.failureDomains.failed[batchNumber] = domain
+}
+
+// clearBatchProofFailure forgets the failed attempts to prove the batch once
+// it has been proven.
+func (a *Aggregator) clearBatchProofFailure(batchNumber uint64) {
+	a.failureDomains.mutex.Lock()
+	defer a.failureDomains.mutex.Unlock()
+	delete(a.failureDomains.failed, batchNumber)
+}
+
+// proverCanRetryBatch returns false if the last attempt to prove the batch
+// failed in the failure domain of the prover and a prover in another domain is
+// connected to retry it. The failures of the batches already verified are
+// forgotten.
+func (a *Aggregator) proverCanRetryBatch(prover proverInterface, batchNumber, lastVerifiedBatchNumber uint64) bool {
+	if !a.cfg.Provers.SeparateRetryDomains {
+		return true
+	}
+
+	a.failureDomains.mutex.Lock()
+	defer a.failureDomains.mutex.Unlock()
+
+	for n := range a.failureDomains.failed {
+		if n <= lastVerifiedBatchNumber {
+			delete(a.failureDomains.failed, n)
+		}
+	}
+
+	failedDomain, ok := a.failureDomains.failed[batchNumber]
+	if !ok || a.proverFailureDomain(prover) != failedDomain {
+		return true
+	}
+	for domain := range a.failureDomains.connected {
+		if domain != failedDomain {
+			return false
+		}
+	}
+	// no prover out of the failed domain, retrying in the same domain is
+	// better than not proving the batch at all
+	return true
+}
@@ -0,0 +1,121 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// isProverFailure returns true if the error has been reported by the prover,
+// either as a failed proof or as a malformed response. Preempted and canceled
+// proofs, as well as connection errors, are not failures of the prover.
+func isProverFailure(err error) bool {
+	if errors.Is(err, ErrProofPreempted) || errors.Is(err, prover.ErrProofCanceled) {
+		return false
+	}
+	return errors.Is(err, prover.ErrBadRequest) ||
+		errors.Is(err, prover.ErrProverInternalError) ||
+		errors.Is(err, prover.ErrProverCompletedError) ||
+		errors.Is(err, prover.ErrBadProverResponse) ||
+		errors.Is(err, prover.ErrProverResourceExhausted) ||
+		errors.Is(err, prover.ErrUnspecified) ||
+		errors.Is(err, prover.ErrUnknown)
+}
+
+// captureFailedProof stores the inputs sent to the prover together with the
+// error it returned, so the failure can be reproduced by the prover team, and
+// exports them to the object storage when configured. Errors not reported by
+// the prover are ignored.
+func (a *Aggregator) captureFailedProof(level string, proof *state.Proof, prover proverInterface, input interface{}, proofErr error) {
+	if !isProverFailure(proofErr) {
+		return
+	}
+	metrics.FailedProof(level)
+	if !a.cfg.FailedProofs.CaptureEnabled {
+		return
+	}
+
+	log := log.WithFields("prover", prover.Name(), "batches", fmt.Sprintf("%d-%d", proof.BatchNumber, proof.BatchNumberFinal))
+
+	inputProver, ok := input.(string)
+	if !ok {
+		b, err := json.Marshal(input)
+		if err != nil {
+			log.Errorf("Failed to serialize the input of the failed proof: %v", err)
+			return
+		}
+		inputProver = string(b)
+	}
+
+	failedProof := &state.FailedProof{
+		Kind:             level,
+		BatchNumber:      proof.BatchNumber,
+		BatchNumberFinal: proof.BatchNumberFinal,
+		ProofID:          proof.ProofID,
+		Prover:           prover.Name(),
+		ProverID:         prover.ID(),
+		InputProver:      inputProver,
+		Error:            proofErr.Error(),
+	}
+	if err := a.state.AddFailedProof(a.ctx, failedProof, nil); err != nil {
+		log.Errorf("Failed to store the failed proof: %v", err)
+		return
+	}
+	log.Infof("Failed %s proof captured with id %d", level, failedProof.ID)
+
+	if a.cfg.FailedProofs.ExportURL != "" {
+		go a.exportFailedProof(failedProof)
+	}
+}
+
+// exportFailedProof uploads the failed proof to the object storage as
+// <ExportURL>/<id>.json and records the export.
+func (a *Aggregator) exportFailedProof(failedProof *state.FailedProof) {
+	log := log.WithFields("failedProofId", failedProof.ID)
+
+	body, err := json.Marshal(failedProof)
+	if err != nil {
+		log.Errorf("Failed to serialize the failed proof to export it: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.cfg.FailedProofs.ExportTimeout.Duration)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/%d.json", strings.TrimSuffix(a.cfg.FailedProofs.ExportURL, "/"), failedProof.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		log.Errorf("Failed to create the failed proof export request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.cfg.FailedProofs.ExportAuthorization != "" {
+		req.Header.Set("Authorization", a.cfg.FailedProofs.ExportAuthorization)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		log.Warnf("Failed to export the failed proof: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		log.Warnf("Failed to export the failed proof: unexpected status %s", resp.Status)
+		return
+	}
+
+	if err := a.state.SetFailedProofExported(a.ctx, failedProof.ID, nil); err != nil {
+		log.Warnf("Failed to record the export of the failed proof: %v", err)
+		return
+	}
+	log.Debugf("Failed proof exported to %s", url)
+}
@@ -0,0 +1,147 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	xlayermetrics "github.com/0xPolygonHermez/zkevm-aggregator/xlayer/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// finalProofHexLength is the length of a 0x prefixed final proof made of 24
+// field elements of 32 bytes each.
+const finalProofHexLength = 24*32*2 + 2
+
+var (
+	// ErrInvalidInjectedProof is returned when an injected final proof does
+	// not match the local view of the batches it claims to verify.
+	ErrInvalidInjectedProof = errors.New("invalid injected final proof")
+	// ErrProofVerificationInProgress is returned when a final proof can not be
+	// accepted because another one is being settled.
+	ErrProofVerificationInProgress = errors.New("a final proof verification is already in progress")
+)
+
+// InjectedFinalProof is a final proof produced out-of-band (e.g. by a backup
+// prover farm) for the batch range [BatchNumber, BatchNumberFinal].
+type InjectedFinalProof struct {
+	BatchNumber      uint64      `json:"batchNumber"`
+	BatchNumberFinal uint64      `json:"batchNumberFinal"`
+	Proof            string      `json:"proof"`
+	NewStateRoot     common.Hash `json:"newStateRoot"`
+	NewLocalExitRoot common.Hash `json:"newLocalExitRoot"`
+	NewAccInputHash  common.Hash `json:"newAccInputHash"`
+}
+
+// InjectFinalProof validates a final proof produced out-of-band and hands it
+// over to the settlement loop, as if it had been built by a connected prover.
+func (a *Aggregator) InjectFinalProof(ctx context.Context, injected *InjectedFinalProof) error {
+	log := log.WithFields("batches", fmt.Sprintf("%d-%d", injected.BatchNumber, injected.BatchNumberFinal))
+
+	if err := a.validateInjectedFinalProof(ctx, injected); err != nil {
+		log.Warnf("Rejected injected final proof: %v", err)
+		return err
+	}
+
+	a.timeSendFinalProofMutex.Lock()
+	if a.verifyingProof {
+		a.timeSendFinalProofMutex.Unlock()
+		return ErrProofVerificationInProgress
+	}
+	// keep the provers from building a final proof while this one is settled
+	a.verifyingProof = true
+	a.timeSendFinalProofMutex.Unlock()
+
+	// keep other aggregator replicas from building or settling a final proof
+	// for the same range, the lock is released when the verification ends
+	locked, err := a.tryLockFinalProofRange(ctx, injected.BatchNumber, injected.BatchNumberFinal)
+	if err != nil {
+		a.endProofVerification()
+		return fmt.Errorf("failed to lock final proof batch range: %w", err)
+	}
+	if !locked {
+		a.endProofVerification()
+		return fmt.Errorf("%w: %v", ErrProofVerificationInProgress, errFinalProofRangeLocked)
+	}
+
+	proofID := fmt.Sprintf("injected-%d-%d", injected.BatchNumber, injected.BatchNumberFinal)
+	msg := finalProofMsg{
+		proverName: "injected",
+		proverID:   proofID,
+		recursiveProof: &state.Proof{
+			BatchNumber:      injected.BatchNumber,
+			BatchNumberFinal: injected.BatchNumberFinal,
+			ProofID:          &proofID,
+		},
+		finalProof: &prover.FinalProof{
+			Proof: injected.Proof,
+			Public: &prover.PublicInputsExtended{
+				NewStateRoot:     injected.NewStateRoot.Bytes(),
+				NewAccInputHash:  injected.NewAccInputHash.Bytes(),
+				NewLocalExitRoot: injected.NewLocalExitRoot.Bytes(),
+				NewBatchNum:      injected.BatchNumberFinal,
+			},
+		},
+		injected: true,
+	}
+	a.setBuiltFinalProof(proofID, msg.finalProof)
+
+	select {
+	case <-ctx.Done():
+		a.endProofVerification()
+		return ctx.Err()
+	case <-a.ctx.Done():
+		a.endProofVerification()
+		return a.ctx.Err()
+	case a.finalProof <- msg:
+	}
+
+	xlayermetrics.CodePathHit(xlayermetrics.InjectedFinalProofCodePath)
+	log.Infof("Injected final proof %s accepted, sending it to be settled", proofID)
+	return nil
+}
+
+// validateInjectedFinalProof checks the injected proof is the next one to be
+// verified and that its public outputs match the batches stored locally.
+func (a *Aggregator) validateInjectedFinalProof(ctx context.Context, injected *InjectedFinalProof) error {
+	if injected.BatchNumberFinal < injected.BatchNumber {
+		return fmt.Errorf("%w: batch range %d-%d is empty", ErrInvalidInjectedProof, injected.BatchNumber, injected.BatchNumberFinal)
+	}
+	if !strings.HasPrefix(injected.Proof, "0x") || len(injected.Proof) != finalProofHexLength {
+		return fmt.Errorf("%w: proof must be a 0x prefixed string of length %d", ErrInvalidInjectedProof, finalProofHexLength)
+	}
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		return err
+	}
+	if injected.BatchNumber != lastVerifiedBatchNumber+1 {
+		return fmt.Errorf("%w: proof starts at batch %d but next batch to verify is %d", ErrInvalidInjectedProof, injected.BatchNumber, lastVerifiedBatchNumber+1)
+	}
+
+	finalBatch, _, err := a.state.GetBatch(ctx, injected.BatchNumberFinal, nil)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get batch %d: %v", ErrInvalidInjectedProof, injected.BatchNumberFinal, err)
+	}
+	// the roots of the batches reconstructed from L1 are unknown, the acc
+	// input hash is the only output that can be checked locally
+	if finalBatch.HasRoots() {
+		if finalBatch.StateRoot != injected.NewStateRoot {
+			return fmt.Errorf("%w: state root %s does not match expected %s", ErrInvalidInjectedProof, injected.NewStateRoot, finalBatch.StateRoot)
+		}
+		if finalBatch.LocalExitRoot != injected.NewLocalExitRoot {
+			return fmt.Errorf("%w: local exit root %s does not match expected %s", ErrInvalidInjectedProof, injected.NewLocalExitRoot, finalBatch.LocalExitRoot)
+		}
+	} else {
+		log.Warnf("Batch %d reconstructed from L1, settling the injected roots unchecked", injected.BatchNumberFinal)
+	}
+	if finalBatch.AccInputHash != injected.NewAccInputHash {
+		return fmt.Errorf("%w: acc input hash %s does not match expected %s", ErrInvalidInjectedProof, injected.NewAccInputHash, finalBatch.AccInputHash)
+	}
+
+	return nil
+}
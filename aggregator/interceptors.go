@@ -0,0 +1,70 @@
+package aggregator
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// unaryServerInterceptor logs, measures and recovers from panics the unary
+// calls of the prover facing gRPC server.
+func unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredPanic(ctx, info.FullMethod, r)
+		}
+		logRequest(ctx, info.FullMethod, time.Since(start), err)
+	}()
+	return handler(ctx, req)
+}
+
+// streamServerInterceptor logs, measures and recovers from panics the streams
+// of the prover facing gRPC server. A panic while serving a prover channel
+// closes that channel with an error, the prover reconnects and the rest of the
+// aggregator keeps running.
+func streamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx := stream.Context()
+	start := time.Now()
+	log.WithFields("method", info.FullMethod, "peer", peerAddr(ctx)).Debug("gRPC stream opened")
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredPanic(ctx, info.FullMethod, r)
+		}
+		logRequest(ctx, info.FullMethod, time.Since(start), err)
+	}()
+	return handler(srv, stream)
+}
+
+// recoveredPanic logs a panic recovered while serving a gRPC call and returns
+// the error sent to the client.
+func recoveredPanic(ctx context.Context, method string, r interface{}) error {
+	metrics.GRPCPanic(method)
+	log.WithFields("method", method, "peer", peerAddr(ctx)).
+		Errorf("Recovered from panic serving gRPC call: %v\n%s", r, debug.Stack())
+	return status.Errorf(codes.Internal, "internal error serving %s", method)
+}
+
+func logRequest(ctx context.Context, method string, elapsed time.Duration, err error) {
+	metrics.GRPCRequest(method, elapsed)
+	log := log.WithFields("method", method, "peer", peerAddr(ctx), "elapsed", elapsed, "code", status.Code(err))
+	if err != nil {
+		log.Warnf("gRPC call failed: %v", err)
+		return
+	}
+	log.Debug("gRPC call served")
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
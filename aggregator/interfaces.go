@@ -17,6 +17,7 @@ import (
 type proverInterface interface {
 	Name() string
 	ID() string
+	Version() string
 	Addr() string
 	IsIdle() (bool, error)
 	BatchProof(input *prover.StatelessInputProver) (*string, error)
@@ -24,6 +25,7 @@ type proverInterface interface {
 	FinalProof(inputProof string, aggregatorAddr string) (*string, error)
 	WaitRecursiveProof(ctx context.Context, proofID string) (string, common.Hash, error)
 	WaitFinalProof(ctx context.Context, proofID string) (*prover.FinalProof, error)
+	CancelProofRequest(proofID string) error
 }
 
 // etherman contains the methods required to interact with ethereum
@@ -33,6 +35,13 @@ type etherman interface {
 	BuildTrustedVerifyBatchesTxData(lastVerifiedBatch, newVerifiedBatch uint64, inputs *ethmanTypes.FinalProofInputs, beneficiary common.Address) (to *common.Address, data []byte, err error)
 	GetLatestBlockHeader(ctx context.Context) (*types.Header, error)
 	GetBatchAccInputHash(ctx context.Context, batchNumber uint64) (common.Hash, error)
+	GetBatchStateRoot(ctx context.Context, batchNumber uint64) (common.Hash, error)
+	HasTrustedAggregatorRole(ctx context.Context, account common.Address) (bool, error)
+	GetRollupVerifier(ctx context.Context) (*ethmanTypes.RollupVerifier, error)
+	GetExitRoots(ctx context.Context) (*ethmanTypes.ExitRoots, error)
+	GetRollupSequences(ctx context.Context, fromBlock, toBlock uint64) ([]ethmanTypes.RollupSequence, error)
+	CurrentNonce(ctx context.Context, account common.Address) (uint64, error)
+	PendingNonce(ctx context.Context, account common.Address) (uint64, error)
 }
 
 // aggregatorTxProfitabilityChecker interface for different profitability
@@ -46,7 +55,9 @@ type stateInterface interface {
 	BeginStateTransaction(ctx context.Context) (pgx.Tx, error)
 	CheckProofContainsCompleteSequences(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) (bool, error)
 	GetProofReadyToVerify(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*state.Proof, error)
-	GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx) (*state.Proof, *state.Proof, error)
+	GetProofsToAggregate(ctx context.Context, maxBatches uint64, dbTx pgx.Tx) (*state.Proof, *state.Proof, error)
+	GetOldestProofToAggregate(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*state.Proof, error)
+	GetProofByID(ctx context.Context, proofID string, dbTx pgx.Tx) (*state.Proof, error)
 	AddGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error
 	UpdateGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error
 	DeleteGeneratedProofs(ctx context.Context, batchNumber uint64, batchNumberFinal uint64, dbTx pgx.Tx) error
@@ -54,9 +65,32 @@ type stateInterface interface {
 	CleanupGeneratedProofs(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
 	CleanupLockedProofs(ctx context.Context, duration string, dbTx pgx.Tx) (int64, error)
 	CheckProofExistsForBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (bool, error)
+	GetProofPartitions(ctx context.Context, dbTx pgx.Tx) ([]state.ProofPartition, error)
+	AddProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error
+	DropProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error
+	GetProofs(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Proof, error)
 	AddSequence(ctx context.Context, sequence state.Sequence, dbTx pgx.Tx) error
 	AddBatch(ctx context.Context, batch *state.Batch, datastream []byte, dbTx pgx.Tx) error
 	GetBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, []byte, error)
+	GetBatches(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Batch, error)
+	GetSequences(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Sequence, error)
 	DeleteBatchesOlderThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
 	DeleteBatchesNewerThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
+	AddAuditLogEntry(ctx context.Context, entry *state.AuditLogEntry, dbTx pgx.Tx) error
+	GetAuditLog(ctx context.Context, action string, limit uint64, dbTx pgx.Tx) ([]state.AuditLogEntry, error)
+	AddBatchStats(ctx context.Context, stats *state.BatchStats, dbTx pgx.Tx) error
+	GetBatchStats(ctx context.Context, fromBatchNumber, toBatchNumber uint64, dbTx pgx.Tx) ([]state.BatchStats, error)
+	GetUnprovenBatchNumbers(ctx context.Context, lastVerifiedBatchNumber uint64, dbTx pgx.Tx) ([]uint64, error)
+	AddL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error
+	UpdateL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error
+	UpdateL1IntentStatusByMonitoredTxID(ctx context.Context, monitoredTxID common.Hash, status state.L1IntentStatus, dbTx pgx.Tx) error
+	GetUnresolvedL1Intents(ctx context.Context, dbTx pgx.Tx) ([]state.L1Intent, error)
+	GetL1Intents(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.L1Intent, error)
+	AddFailedProof(ctx context.Context, failedProof *state.FailedProof, dbTx pgx.Tx) error
+	SetFailedProofExported(ctx context.Context, id uint64, dbTx pgx.Tx) error
+	GetFailedProofs(ctx context.Context, limit uint64, dbTx pgx.Tx) ([]state.FailedProof, error)
+	AddFinalProofPublicInputs(ctx context.Context, publicInputs *state.FinalProofPublicInputs, dbTx pgx.Tx) error
+	GetFinalProofPublicInputs(ctx context.Context, batchNumber uint64, limit uint64, dbTx pgx.Tx) ([]state.FinalProofPublicInputs, error)
+	LockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) error
+	TryLockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) (bool, error)
 }
@@ -0,0 +1,36 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/0xPolygon/cdk-rpc/rpc"
+)
+
+// jsonRPCCall executes a JSON RPC call like rpc.JSONRPCCallWithContext, using
+// the given HTTP client instead of the default one so it goes through the
+// configured proxy.
+func jsonRPCCall(ctx context.Context, client *http.Client, url, method string, params ...interface{}) (rpc.Response, error) {
+	httpReq, err := rpc.BuildJsonHTTPRequest(ctx, url, method, params...)
+	if err != nil {
+		return rpc.Response{}, err
+	}
+
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		return rpc.Response{}, err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode != http.StatusOK {
+		return rpc.Response{}, fmt.Errorf("invalid status code, expected: %v, found: %v", http.StatusOK, httpRes.StatusCode)
+	}
+
+	var res rpc.Response
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		return rpc.Response{}, err
+	}
+	return res, nil
+}
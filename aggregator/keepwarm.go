@@ -0,0 +1,70 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	xlayermetrics "github.com/0xPolygonHermez/zkevm-aggregator/xlayer/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrKeepWarmFailed is returned when a prover fails a keepwarm job.
+var ErrKeepWarmFailed = errors.New("prover failed keepwarm job")
+
+// keepWarm re-proves the next batch pending verification on an idle prover,
+// discarding the result. An error is returned only if the prover failed the
+// job or returned a state root different from the expected one, meaning it
+// should not be trusted with real batches.
+func (a *Aggregator) keepWarm(ctx context.Context, prover proverInterface) error {
+	log := log.WithFields(
+		"prover", prover.Name(),
+		"proverId", prover.ID(),
+		"proverAddr", prover.Addr(),
+	)
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		log.Warnf("Skipping keepwarm job, failed to get last verified batch: %v", err)
+		return nil
+	}
+
+	batch, _, err := a.state.GetBatch(ctx, lastVerifiedBatchNumber+1, nil)
+	if err != nil {
+		log.Debugf("Skipping keepwarm job, batch %d not available: %v", lastVerifiedBatchNumber+1, err)
+		return nil
+	}
+	log = log.WithFields("batch", batch.BatchNumber)
+
+	inputProver, err := a.buildInputProver(ctx, batch)
+	if err != nil {
+		log.Warnf("Skipping keepwarm job, failed to build input prover: %v", err)
+		return nil
+	}
+
+	log.Info("Sending keepwarm job to idle prover")
+	metrics.KeepWarmJob()
+	xlayermetrics.CodePathHit(xlayermetrics.KeepWarmCodePath)
+
+	proofID, err := prover.BatchProof(inputProver)
+	if err != nil {
+		metrics.KeepWarmFailure()
+		return fmt.Errorf("%w: %v", ErrKeepWarmFailed, err)
+	}
+
+	_, stateRoot, err := prover.WaitRecursiveProof(ctx, *proofID)
+	if err != nil {
+		metrics.KeepWarmFailure()
+		return fmt.Errorf("%w: proof %s: %v", ErrKeepWarmFailed, *proofID, err)
+	}
+
+	if batch.HasRoots() && (stateRoot != common.Hash{}) && stateRoot != batch.StateRoot {
+		metrics.KeepWarmFailure()
+		return fmt.Errorf("%w: proof %s state root %s does not match expected %s", ErrKeepWarmFailed, *proofID, stateRoot, batch.StateRoot)
+	}
+
+	log.Infof("Keepwarm job %s completed", *proofID)
+	return nil
+}
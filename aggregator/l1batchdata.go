@@ -0,0 +1,70 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/accinputhash"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/datastream"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// buildBatchFromL1 reconstructs a batch not received from the data stream
+// using the data the L1 synchronizer decoded from the sequencing transaction.
+// The state root and local exit root of the batch are unknown at this point,
+// they are left empty and taken from the prover outputs instead, so the batch
+// is flagged with its source and checked against the acc input hash on L1.
+func (a *Aggregator) buildBatchFromL1(ctx context.Context, batchNumber uint64) (*state.Batch, error) {
+	if batchNumber <= 1 {
+		return nil, fmt.Errorf("batch %d can not be reconstructed from L1", batchNumber)
+	}
+
+	virtualBatch, err := a.l1Syncr.GetVirtualBatchByBatchNumber(ctx, batchNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual batch %d: %w", batchNumber, err)
+	}
+	if virtualBatch == nil {
+		return nil, state.ErrNotFound
+	}
+
+	sequence, err := a.l1Syncr.GetSequenceByBatchNumber(ctx, batchNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequence for batch %d: %w", batchNumber, err)
+	}
+	if sequence == nil {
+		return nil, state.ErrNotFound
+	}
+
+	oldBatch, _, err := a.state.GetBatch(ctx, batchNumber-1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch %d: %w", batchNumber-1, err)
+	}
+
+	batch := &state.Batch{
+		BatchNumber: batchNumber,
+		Coinbase:    virtualBatch.Coinbase,
+		BatchL2Data: virtualBatch.BatchL2Data,
+		L1InfoRoot:  sequence.L1InfoRoot,
+		Timestamp:   sequence.Timestamp,
+		ChainID:     a.cfg.ChainID,
+		ForkID:      virtualBatch.ForkID,
+		Type:        datastream.BatchType_BATCH_TYPE_REGULAR,
+		Source:      state.BatchSourceL1,
+	}
+
+	batch.AccInputHash, err = accinputhash.CalculateAccInputHash(oldBatch.AccInputHash, batch.BatchL2Data, batch.L1InfoRoot, uint64(batch.Timestamp.Unix()), batch.Coinbase, common.Hash{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate acc input hash for batch %d: %w", batchNumber, err)
+	}
+
+	// the data stream will override this batch if it ever delivers it
+	err = a.state.AddBatch(ctx, batch, []byte{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store batch %d: %w", batchNumber, err)
+	}
+
+	log.Warnf("Batch %d not received from the data stream, reconstructed from L1 sequencing data", batchNumber)
+	return batch, nil
+}
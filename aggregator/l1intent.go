@@ -0,0 +1,184 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-ethtx-manager/ethtxmanager"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnresolvedL1Intent is returned when a verification tx can not be sent
+// because a previous one may have been broadcast and its outcome is unknown.
+var ErrUnresolvedL1Intent = errors.New("a previous verification tx may still be in flight")
+
+// addVerifyTxWithIntent persists the intent to send the verification tx of the
+// proof before handing it to the eth tx manager, with the nonce chosen up
+// front. After a crash, the intent tells which nonce and calldata to look for
+// on L1, so the range is neither sent twice nor skipped.
+func (a *Aggregator) addVerifyTxWithIntent(ctx context.Context, proof *state.Proof, to *common.Address, data []byte) (common.Hash, error) {
+	unresolved, err := a.resolveL1Intents(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to resolve previous L1 intents: %w", err)
+	}
+	if len(unresolved) > 0 {
+		return common.Hash{}, fmt.Errorf("%w: batches %d-%d, nonce %d", ErrUnresolvedL1Intent,
+			unresolved[0].BatchNumber, unresolved[0].BatchNumberFinal, unresolved[0].Nonce)
+	}
+
+	nonce, err := a.nextSenderNonce(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get the nonce of the verification tx: %w", err)
+	}
+
+	intent := &state.L1Intent{
+		BatchNumber:      proof.BatchNumber,
+		BatchNumberFinal: proof.BatchNumberFinal,
+		CalldataHash:     crypto.Keccak256Hash(data),
+		Nonce:            nonce,
+		Status:           state.L1IntentPending,
+	}
+	err = a.state.AddL1Intent(ctx, intent, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to persist the L1 intent: %w", err)
+	}
+
+	monitoredTxID, err := a.ethTxManager.Add(ctx, to, &nonce, big.NewInt(0), data, a.cfg.GasOffset, nil)
+	if err != nil {
+		intent.Status = state.L1IntentAbandoned
+		if err := a.state.UpdateL1Intent(ctx, intent, nil); err != nil {
+			log.Errorf("Failed to abandon L1 intent %d: %v", intent.ID, err)
+		}
+		return monitoredTxID, err
+	}
+
+	intent.MonitoredTxID = &monitoredTxID
+	intent.Status = state.L1IntentSent
+	if err := a.state.UpdateL1Intent(ctx, intent, nil); err != nil {
+		// the tx is monitored, the intent is resolved by its nonce if needed
+		log.Errorf("Failed to update L1 intent %d with monitored tx %s: %v", intent.ID, monitoredTxID, err)
+	}
+	return monitoredTxID, nil
+}
+
+// nextSenderNonce returns the nonce the eth tx manager would pick for the next
+// tx of the sender.
+func (a *Aggregator) nextSenderNonce(ctx context.Context) (uint64, error) {
+	created, err := a.ethTxManager.ResultsByStatus(ctx, []ethtxmanager.MonitoredTxStatus{ethtxmanager.MonitoredTxStatusCreated})
+	if err != nil {
+		return 0, err
+	}
+	if len(created) > 0 {
+		var nonce uint64
+		for _, result := range created {
+			if result.Nonce > nonce {
+				nonce = result.Nonce
+			}
+		}
+		return nonce + 1, nil
+	}
+	return a.etherman.PendingNonce(ctx, common.HexToAddress(a.cfg.SenderAddress))
+}
+
+// resolveL1Intents reconciles the pending and sent intents with L1 and the eth
+// tx manager, returning the ones whose outcome is still unknown.
+func (a *Aggregator) resolveL1Intents(ctx context.Context) ([]state.L1Intent, error) {
+	intents, err := a.state.GetUnresolvedL1Intents(ctx, nil)
+	if err != nil || len(intents) == 0 {
+		return nil, err
+	}
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		return nil, err
+	}
+
+	var unresolved []state.L1Intent
+	for i := range intents {
+		intent := &intents[i]
+		status, err := a.resolveL1Intent(ctx, intent, lastVerifiedBatchNumber)
+		if err != nil {
+			return nil, err
+		}
+		if status != intent.Status {
+			log.Infof("L1 intent %d for batches %d-%d with nonce %d resolved from %s to %s",
+				intent.ID, intent.BatchNumber, intent.BatchNumberFinal, intent.Nonce, intent.Status, status)
+			intent.Status = status
+			if err := a.state.UpdateL1Intent(ctx, intent, nil); err != nil {
+				return nil, err
+			}
+		}
+		if status == state.L1IntentPending || status == state.L1IntentSent {
+			unresolved = append(unresolved, *intent)
+		}
+	}
+	return unresolved, nil
+}
+
+// resolveL1Intent determines the status of an intent. The batch range being
+// verified on L1 settles it, otherwise the eth tx manager is asked about the
+// monitored tx. If the eth tx manager does not know the tx, the crash happened
+// before it was persisted and the nonce tells whether it was broadcast.
+func (a *Aggregator) resolveL1Intent(ctx context.Context, intent *state.L1Intent, lastVerifiedBatchNumber uint64) (state.L1IntentStatus, error) {
+	if lastVerifiedBatchNumber >= intent.BatchNumberFinal {
+		return state.L1IntentMined, nil
+	}
+
+	if intent.MonitoredTxID != nil {
+		result, err := a.ethTxManager.Result(ctx, *intent.MonitoredTxID)
+		switch {
+		case errors.Is(err, ethtxmanager.ErrNotFound):
+		case err != nil:
+			return intent.Status, err
+		case result.Status == ethtxmanager.MonitoredTxStatusFailed:
+			return state.L1IntentFailed, nil
+		case result.Status == ethtxmanager.MonitoredTxStatusMined, result.Status == ethtxmanager.MonitoredTxStatusSafe,
+			result.Status == ethtxmanager.MonitoredTxStatusFinalized:
+			// mined without verifying the range, it reverted
+			return state.L1IntentFailed, nil
+		default:
+			return state.L1IntentSent, nil
+		}
+	}
+
+	sender := common.HexToAddress(a.cfg.SenderAddress)
+	minedNonce, err := a.etherman.CurrentNonce(ctx, sender)
+	if err != nil {
+		return intent.Status, err
+	}
+	if minedNonce > intent.Nonce {
+		// the nonce was used by a tx that did not verify the range
+		return state.L1IntentAbandoned, nil
+	}
+	pendingNonce, err := a.etherman.PendingNonce(ctx, sender)
+	if err != nil {
+		return intent.Status, err
+	}
+	if pendingNonce > intent.Nonce {
+		// a tx with the nonce is waiting to be mined, it may be this one
+		return intent.Status, nil
+	}
+	return state.L1IntentAbandoned, nil
+}
+
+// updateL1IntentFromResult records the outcome of a monitored verification tx
+// in its intent.
+func (a *Aggregator) updateL1IntentFromResult(result ethtxmanager.MonitoredTxResult) {
+	var status state.L1IntentStatus
+	switch result.Status {
+	case ethtxmanager.MonitoredTxStatusFailed:
+		status = state.L1IntentFailed
+	case ethtxmanager.MonitoredTxStatusMined, ethtxmanager.MonitoredTxStatusSafe, ethtxmanager.MonitoredTxStatusFinalized:
+		status = state.L1IntentMined
+	default:
+		return
+	}
+	if err := a.state.UpdateL1IntentStatusByMonitoredTxID(a.ctx, result.ID, status, nil); err != nil {
+		log.Errorf("Failed to update L1 intent of monitored tx %s: %v", result.ID, err)
+	}
+}
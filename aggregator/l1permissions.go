@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrSenderNotTrustedAggregator is returned when the configured sender is not
+// allowed to verify batches on the RollupManager.
+var ErrSenderNotTrustedAggregator = errors.New("sender does not have the trusted aggregator role")
+
+// checkL1Permissions checks that the configured sender is allowed to send the
+// final proofs to L1. Settling through the AggLayer does not require any L1
+// permission.
+func (a *Aggregator) checkL1Permissions(ctx context.Context) error {
+	if a.cfg.SettlementBackend == AggLayer {
+		return nil
+	}
+
+	sender := common.HexToAddress(a.cfg.SenderAddress)
+	authorized, err := a.etherman.HasTrustedAggregatorRole(ctx, sender)
+	if err != nil {
+		return fmt.Errorf("failed to check the trusted aggregator role of %s: %w", sender, err)
+	}
+	metrics.SenderAuthorized(authorized)
+	if !authorized {
+		return fmt.Errorf("%w: %s", ErrSenderNotTrustedAggregator, sender)
+	}
+	return nil
+}
+
+// monitorL1Permissions periodically checks the L1 permissions of the sender,
+// so a revoked role is reported as soon as possible instead of through
+// reverted verification txs.
+func (a *Aggregator) monitorL1Permissions() {
+	ticker := time.NewTicker(a.cfg.L1PermissionsCheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			err := a.checkL1Permissions(a.ctx)
+			switch {
+			case errors.Is(err, ErrSenderNotTrustedAggregator):
+				log.Errorf("L1 permissions check failed, final proofs will be rejected by L1: %v", err)
+			case err != nil:
+				log.Warnf("L1 permissions check could not be completed: %v", err)
+			}
+		}
+	}
+}
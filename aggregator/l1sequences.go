@@ -0,0 +1,139 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-synchronizer-l1/synchronizer"
+)
+
+// SequenceSource is where the aggregator reads the sequences of batches from
+type SequenceSource string
+
+const (
+	// SequenceSourceSynchronizer reads the sequences decoded by the L1
+	// synchronizer from the sequencing transactions
+	SequenceSourceSynchronizer SequenceSource = "synchronizer"
+	// SequenceSourceRollupManager reads the sequences from the
+	// OnSequenceBatches events and the sequenced batches data of the
+	// RollupManager
+	SequenceSourceRollupManager SequenceSource = "rollupmanager"
+)
+
+// l1Sequences are the sequences of the rollup read from the RollupManager,
+// sorted by batch number.
+type l1Sequences struct {
+	mutex     sync.RWMutex
+	sequences []synchronizer.SequencedBatches
+	// last L1 block whose events have been read
+	lastBlock uint64
+}
+
+// get returns the sequence containing the batch, nil if it has not been read
+// yet.
+func (s *l1Sequences) get(batchNumber uint64) *synchronizer.SequencedBatches {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	i := sort.Search(len(s.sequences), func(i int) bool {
+		return s.sequences[i].ToBatchNumber >= batchNumber
+	})
+	if i == len(s.sequences) || s.sequences[i].FromBatchNumber > batchNumber {
+		return nil
+	}
+	sequence := s.sequences[i]
+	return &sequence
+}
+
+// dropFrom forgets the sequences containing the batch and the following ones,
+// so they are read again from the L1 block of the last sequence kept.
+func (s *l1Sequences) dropFrom(batchNumber uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.sequences), func(i int) bool {
+		return s.sequences[i].ToBatchNumber >= batchNumber
+	})
+	if i == len(s.sequences) {
+		return
+	}
+	s.sequences = s.sequences[:i]
+	s.lastBlock = 0
+	if i > 0 {
+		s.lastBlock = s.sequences[i-1].L1BlockNumber
+	}
+}
+
+// getSequenceByBatchNumber returns the sequence containing the batch from the
+// configured source, nil if the batch has not been sequenced yet.
+func (a *Aggregator) getSequenceByBatchNumber(ctx context.Context, batchNumber uint64) (*synchronizer.SequencedBatches, error) {
+	if a.cfg.Sequences.Source != SequenceSourceRollupManager {
+		return a.l1Syncr.GetSequenceByBatchNumber(ctx, batchNumber)
+	}
+	return a.l1Sequences.get(batchNumber), nil
+}
+
+// syncL1Sequences reads the sequences of the rollup from the RollupManager
+// events until the aggregator stops.
+func (a *Aggregator) syncL1Sequences() {
+	ticker := time.NewTicker(a.cfg.Sequences.CheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		if err := a.readL1Sequences(a.ctx); err != nil {
+			log.Errorf("Failed to read the sequences from the RollupManager: %v", err)
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readL1Sequences reads the sequences made since the last L1 block read up to
+// the latest one.
+func (a *Aggregator) readL1Sequences(ctx context.Context) error {
+	header, err := a.etherman.GetLatestBlockHeader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest L1 block: %w", err)
+	}
+	toBlock := header.Number.Uint64()
+
+	a.l1Sequences.mutex.RLock()
+	fromBlock := a.l1Sequences.lastBlock + 1
+	a.l1Sequences.mutex.RUnlock()
+	if fromBlock == 1 {
+		fromBlock = a.cfg.Synchronizer.Synchronizer.GenesisBlockNumber
+	}
+	if fromBlock > toBlock {
+		return nil
+	}
+
+	rollupSequences, err := a.etherman.GetRollupSequences(ctx, fromBlock, toBlock)
+	if err != nil {
+		return err
+	}
+
+	a.l1Sequences.mutex.Lock()
+	defer a.l1Sequences.mutex.Unlock()
+	for _, s := range rollupSequences {
+		// the sequences read again after a reorg replace the dropped ones
+		n := len(a.l1Sequences.sequences)
+		if n > 0 && a.l1Sequences.sequences[n-1].ToBatchNumber >= s.FromBatchNumber {
+			continue
+		}
+		a.l1Sequences.sequences = append(a.l1Sequences.sequences, synchronizer.SequencedBatches{
+			FromBatchNumber: s.FromBatchNumber,
+			ToBatchNumber:   s.ToBatchNumber,
+			L1BlockNumber:   s.L1BlockNumber,
+			Timestamp:       s.Timestamp,
+		})
+		log.Debugf("Sequence of batches %d-%d read from L1 block %d", s.FromBatchNumber, s.ToBatchNumber, s.L1BlockNumber)
+	}
+	a.l1Sequences.lastBlock = toBlock
+	return nil
+}
@@ -0,0 +1,37 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-synchronizer-l1/synchronizer"
+)
+
+// isSequenceMature returns true if the sequence has been on L1 for long enough
+// to prove its batches, as configured in BatchMaturity. Proving batches of a
+// sequence that may still be reorged out of L1 wastes the proofs if the
+// sequencer sequences them again.
+func (a *Aggregator) isSequenceMature(ctx context.Context, sequence *synchronizer.SequencedBatches) (bool, string, error) {
+	if minAge := a.cfg.BatchMaturity.MinAge.Duration; minAge > 0 {
+		if age := time.Since(sequence.Timestamp); age < minAge {
+			return false, fmt.Sprintf("sequenced %s ago, waiting for %s", age.Round(time.Second), minAge), nil
+		}
+	}
+
+	if minConfirmations := a.cfg.BatchMaturity.MinL1Confirmations; minConfirmations > 0 {
+		header, err := a.etherman.GetLatestBlockHeader(ctx)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to get the latest L1 block: %w", err)
+		}
+		var confirmations uint64
+		if latest := header.Number.Uint64(); latest > sequence.L1BlockNumber {
+			confirmations = latest - sequence.L1BlockNumber
+		}
+		if confirmations < minConfirmations {
+			return false, fmt.Sprintf("sequenced in L1 block %d with %d confirmations, waiting for %d", sequence.L1BlockNumber, confirmations, minConfirmations), nil
+		}
+	}
+
+	return true, "", nil
+}
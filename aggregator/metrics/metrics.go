@@ -1,6 +1,10 @@
 package metrics
 
 import (
+	"fmt"
+	"math/bits"
+	"time"
+
 	"github.com/0xPolygonHermez/zkevm-aggregator/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -9,6 +13,30 @@ const (
 	prefix                      = "aggregator_"
 	currentConnectedProversName = prefix + "current_connected_provers"
 	currentWorkingProversName   = prefix + "current_working_provers"
+	keepWarmJobsName            = prefix + "keepwarm_jobs"
+	keepWarmFailuresName        = prefix + "keepwarm_failures"
+	proofLatencyName            = prefix + "proof_latency_seconds"
+	preemptedProofsName         = prefix + "preempted_proofs"
+	senderAuthorizedName        = prefix + "l1_sender_authorized"
+	verifierChangedName         = prefix + "verifier_changed"
+	oldestProofToAggregateName  = prefix + "oldest_proof_to_aggregate_seconds"
+	grpcRequestLatencyName      = prefix + "grpc_request_duration_seconds"
+	grpcPanicsName              = prefix + "grpc_panics"
+	rejectedProversName         = prefix + "rejected_provers"
+	quarantinedProversName      = prefix + "quarantined_provers"
+	failedProofsName            = prefix + "failed_proofs"
+	unprovenBatchesName         = prefix + "unproven_batches"
+	batchProofTimeEstimateName  = prefix + "batch_proof_time_estimate_seconds"
+	backlogClearEstimateName    = prefix + "backlog_clear_estimate_seconds"
+	pipelineStallsName          = prefix + "pipeline_stalls"
+
+	proofLevelLabelName = "level"
+	methodLabelName     = "method"
+
+	// BatchProofLevel is the recursion level label of batch proofs.
+	BatchProofLevel = "batch"
+	// FinalProofLevel is the recursion level label of final proofs.
+	FinalProofLevel = "final"
 )
 
 // Register the metrics for the sequencer package.
@@ -22,9 +50,99 @@ func Register() {
 			Name: currentWorkingProversName,
 			Help: "[AGGREGATOR] current working provers",
 		},
+		{
+			Name: senderAuthorizedName,
+			Help: "[AGGREGATOR] 1 if the L1 sender has the trusted aggregator role, 0 otherwise",
+		},
+		{
+			Name: verifierChangedName,
+			Help: "[AGGREGATOR] 1 if the rollup verifier changed on L1 and final proofs are paused, 0 otherwise",
+		},
+		{
+			Name: quarantinedProversName,
+			Help: "[AGGREGATOR] current connected provers not allowed in the configuration and kept without jobs",
+		},
+		{
+			Name: unprovenBatchesName,
+			Help: "[AGGREGATOR] batches after the last verified one not proven yet",
+		},
+		{
+			Name: batchProofTimeEstimateName,
+			Help: "[AGGREGATOR] moving average of the batch proving time",
+		},
+		{
+			Name: backlogClearEstimateName,
+			Help: "[AGGREGATOR] estimated time to prove the unproven batches up to the final proof",
+		},
+		{
+			Name: oldestProofToAggregateName,
+			Help: "[AGGREGATOR] time the oldest generated proof has been waiting to be aggregated",
+		},
+	}
+
+	counters := []prometheus.CounterOpts{
+		{
+			Name: keepWarmJobsName,
+			Help: "[AGGREGATOR] keepwarm jobs sent to idle provers",
+		},
+		{
+			Name: keepWarmFailuresName,
+			Help: "[AGGREGATOR] keepwarm jobs that failed or returned an unexpected result",
+		},
+		{
+			Name: rejectedProversName,
+			Help: "[AGGREGATOR] connections of provers not allowed in the configuration that have been rejected",
+		},
+		{
+			Name: preemptedProofsName,
+			Help: "[AGGREGATOR] batch proofs preempted to build the final proof",
+		},
+		{
+			Name: pipelineStallsName,
+			Help: "[AGGREGATOR] stalls of the proving pipeline detected by the watchdog",
+		},
+	}
+
+	histogramVecs := []metrics.HistogramVecOpts{
+		{
+			HistogramOpts: prometheus.HistogramOpts{
+				Name:    proofLatencyName,
+				Help:    "[AGGREGATOR] time spent by the prover generating a proof, by recursion level",
+				Buckets: prometheus.ExponentialBuckets(10, 2, 10), //nolint:gomnd
+			},
+			Labels: []string{proofLevelLabelName},
+		},
+		{
+			HistogramOpts: prometheus.HistogramOpts{
+				Name:    grpcRequestLatencyName,
+				Help:    "[AGGREGATOR] duration of the calls and streams served to the provers, by method",
+				Buckets: prometheus.ExponentialBuckets(0.01, 4, 12), //nolint:gomnd
+			},
+			Labels: []string{methodLabelName},
+		},
+	}
+
+	counterVecs := []metrics.CounterVecOpts{
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: grpcPanicsName,
+				Help: "[AGGREGATOR] panics recovered while serving the provers, by method",
+			},
+			Labels: []string{methodLabelName},
+		},
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: failedProofsName,
+				Help: "[AGGREGATOR] proofs the provers failed to generate or returned malformed, by recursion level",
+			},
+			Labels: []string{proofLevelLabelName},
+		},
 	}
 
 	metrics.RegisterGauges(gauges...)
+	metrics.RegisterCounters(counters...)
+	metrics.RegisterCounterVecs(counterVecs...)
+	metrics.RegisterHistogramVecs(histogramVecs...)
 }
 
 // ConnectedProver increments the gauge for the current number of connected
@@ -39,6 +157,23 @@ func DisconnectedProver() {
 	metrics.GaugeDec(currentConnectedProversName)
 }
 
+// RejectedProver increments the counter of rejected prover connections.
+func RejectedProver() {
+	metrics.CounterInc(rejectedProversName)
+}
+
+// QuarantinedProver increments the gauge for the current number of
+// quarantined provers.
+func QuarantinedProver() {
+	metrics.GaugeInc(quarantinedProversName)
+}
+
+// ReleasedQuarantinedProver decrements the gauge for the current number of
+// quarantined provers.
+func ReleasedQuarantinedProver() {
+	metrics.GaugeDec(quarantinedProversName)
+}
+
 // WorkingProver increments the gauge for the current number of working
 // provers.
 func WorkingProver() {
@@ -49,3 +184,91 @@ func WorkingProver() {
 func IdlingProver() {
 	metrics.GaugeDec(currentWorkingProversName)
 }
+
+// SenderAuthorized sets the gauge reporting if the L1 sender has the trusted
+// aggregator role.
+func SenderAuthorized(authorized bool) {
+	var value float64
+	if authorized {
+		value = 1
+	}
+	metrics.GaugeSet(senderAuthorizedName, value)
+}
+
+// VerifierChanged sets the gauge reporting if the rollup verifier changed on
+// L1 and final proofs are paused.
+func VerifierChanged(changed bool) {
+	var value float64
+	if changed {
+		value = 1
+	}
+	metrics.GaugeSet(verifierChangedName, value)
+}
+
+// OldestProofToAggregate sets the gauge for the time the oldest generated
+// proof has been waiting to be aggregated.
+func OldestProofToAggregate(waiting time.Duration) {
+	metrics.GaugeSet(oldestProofToAggregateName, waiting.Seconds())
+}
+
+// KeepWarmJob increments the counter of keepwarm jobs sent to idle provers.
+func KeepWarmJob() {
+	metrics.CounterInc(keepWarmJobsName)
+}
+
+// KeepWarmFailure increments the counter of failed keepwarm jobs.
+func KeepWarmFailure() {
+	metrics.CounterInc(keepWarmFailuresName)
+}
+
+// PreemptedProof increments the counter of batch proofs preempted to build
+// the final proof.
+func PreemptedProof() {
+	metrics.CounterInc(preemptedProofsName)
+}
+
+// PipelineStalled increments the counter of stalls of the proving pipeline.
+func PipelineStalled() {
+	metrics.CounterInc(pipelineStallsName)
+}
+
+// AggregationProofLevel returns the recursion level label of an aggregated
+// proof covering the given number of batches, i.e. the depth of the smallest
+// binary aggregation tree able to cover them.
+func AggregationProofLevel(batches uint64) string {
+	depth := 1
+	if batches > 1 {
+		depth = bits.Len64(batches - 1)
+	}
+	return fmt.Sprintf("aggregation_%d", depth)
+}
+
+// ProofGenerated observes the time a prover took to generate a proof of the
+// given recursion level.
+func ProofGenerated(level string, elapsed time.Duration) {
+	metrics.HistogramVecObserve(proofLatencyName, level, elapsed.Seconds())
+}
+
+// GRPCRequest observes the duration of a call or stream served to a prover.
+func GRPCRequest(method string, elapsed time.Duration) {
+	metrics.HistogramVecObserve(grpcRequestLatencyName, method, elapsed.Seconds())
+}
+
+// GRPCPanic increments the counter of panics recovered while serving the
+// provers.
+func GRPCPanic(method string) {
+	metrics.CounterVecInc(grpcPanicsName, method)
+}
+
+// FailedProof increments the counter of proofs of the given recursion level
+// the provers failed to generate.
+func FailedProof(level string) {
+	metrics.CounterVecInc(failedProofsName, level)
+}
+
+// BacklogEstimated sets the gauges of the proving backlog estimation.
+func BacklogEstimated(unprovenBatches int, batchProofTime, clearTime time.Duration) {
+	metrics.GaugeSet(unprovenBatchesName, float64(unprovenBatches))
+	metrics.GaugeSet(batchProofTimeEstimateName, batchProofTime.Seconds())
+	metrics.GaugeSet(backlogClearEstimateName, clearTime.Seconds())
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationProofLevel(t *testing.T) {
+	testCases := []struct {
+		batches uint64
+		level   string
+	}{
+		{batches: 1, level: "aggregation_1"},
+		{batches: 2, level: "aggregation_1"},
+		{batches: 3, level: "aggregation_2"},
+		{batches: 4, level: "aggregation_2"},
+		{batches: 5, level: "aggregation_3"},
+		{batches: 8, level: "aggregation_3"},
+		{batches: 9, level: "aggregation_4"},
+		{batches: 1024, level: "aggregation_10"},
+		{batches: 1025, level: "aggregation_11"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.level, AggregationProofLevel(tc.batches), "batches %d", tc.batches)
+	}
+}
@@ -0,0 +1,99 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// maintainProofPartitions keeps the proof table partitioned by batch range,
+// creating the partitions of the next batches to prove ahead of time and
+// dropping the ones of the verified batches, so pruning them is instant.
+func (a *Aggregator) maintainProofPartitions() {
+	ticker := time.NewTicker(a.cfg.ProofPartitioning.CheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		if err := a.updateProofPartitions(a.ctx); err != nil {
+			log.Errorf("Failed to maintain the proof partitions: %v", err)
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateProofPartitions creates the missing partitions from the one holding
+// the first unverified batch up to BatchRange*Ahead batches beyond it, and
+// drops the partitions whose batches are all verified.
+func (a *Aggregator) updateProofPartitions(ctx context.Context) error {
+	size := a.cfg.ProofPartitioning.BatchRange
+	if size == 0 {
+		return fmt.Errorf("the proof partition batch range must be greater than 0")
+	}
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		return err
+	}
+
+	partitions, err := a.state.GetProofPartitions(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if partition.ToBatchNumber > lastVerifiedBatchNumber+1 {
+			continue
+		}
+		if err := a.state.DropProofPartition(ctx, partition, nil); err != nil {
+			return fmt.Errorf("failed to drop proof partition %d-%d: %w", partition.FromBatchNumber, partition.ToBatchNumber, err)
+		}
+		log.Infof("Dropped proof partition of the verified batches %d-%d", partition.FromBatchNumber, partition.ToBatchNumber-1)
+	}
+
+	first := (lastVerifiedBatchNumber + 1) / size * size
+	for i := uint64(0); i <= a.cfg.ProofPartitioning.Ahead; i++ {
+		partition := state.ProofPartition{FromBatchNumber: first + i*size, ToBatchNumber: first + (i+1)*size}
+		if overlapsProofPartition(partitions, partition) {
+			continue
+		}
+		if err := a.addProofPartition(ctx, partition); err != nil {
+			return err
+		}
+		log.Infof("Created proof partition for batches %d-%d", partition.FromBatchNumber, partition.ToBatchNumber-1)
+	}
+
+	return nil
+}
+
+func (a *Aggregator) addProofPartition(ctx context.Context, partition state.ProofPartition) error {
+	dbTx, err := a.state.BeginStateTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	if err := a.state.AddProofPartition(ctx, partition, dbTx); err != nil {
+		if rollbackErr := dbTx.Rollback(ctx); rollbackErr != nil {
+			log.Errorf("Failed to rollback the proof partition creation: %v", rollbackErr)
+		}
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// overlapsProofPartition returns true if the partition overlaps any of the
+// existing ones, which happens when the batch range has been reconfigured
+func overlapsProofPartition(partitions []state.ProofPartition, partition state.ProofPartition) bool {
+	for _, p := range partitions {
+		if partition.FromBatchNumber < p.ToBatchNumber && p.FromBatchNumber < partition.ToBatchNumber {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// ErrProofPreempted is returned when a batch proof has been canceled to free
+// its prover for the final proof.
+var ErrProofPreempted = errors.New("proof preempted to build the final proof")
+
+type proverJobKind int
+
+const (
+	batchProofJob proverJobKind = iota
+	aggregatedProofJob
+	finalProofJob
+)
+
+// proverJob is a proof a connected prover is working on.
+type proverJob struct {
+	kind        proverJobKind
+	proverName  string
+	batchNumber uint64
+	startedAt   time.Time
+	// cancel is only set for preemptible jobs
+	cancel    context.CancelFunc
+	preempted bool
+}
+
+// trackProverJob registers the job a prover is working on until the returned
+// function is called. The returned function reports whether the job has been
+// preempted and it is safe to call it more than once.
+func (a *Aggregator) trackProverJob(prover proverInterface, kind proverJobKind, batchNumber uint64, cancel context.CancelFunc) func() bool {
+	job := &proverJob{
+		kind:        kind,
+		proverName:  prover.Name(),
+		batchNumber: batchNumber,
+		startedAt:   time.Now(),
+		cancel:      cancel,
+	}
+	proverID := prover.ID()
+
+	a.proverJobsMutex.Lock()
+	a.proverJobs[proverID] = job
+	a.proverJobsMutex.Unlock()
+
+	return func() bool {
+		a.proverJobsMutex.Lock()
+		defer a.proverJobsMutex.Unlock()
+		if a.proverJobs[proverID] == job {
+			delete(a.proverJobs, proverID)
+		}
+		return job.preempted
+	}
+}
+
+// startPreemptibleBatchProof registers a batch proof job that can be preempted
+// for the final proof. The returned context is canceled on preemption.
+func (a *Aggregator) startPreemptibleBatchProof(ctx context.Context, prover proverInterface, batchNumber uint64) (context.Context, func() bool) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	untrack := a.trackProverJob(prover, batchProofJob, batchNumber, cancel)
+	return jobCtx, func() bool {
+		preempted := untrack()
+		cancel()
+		return preempted
+	}
+}
+
+// cancelPreemptedProof asks the prover to stop generating a preempted proof,
+// so it becomes idle as soon as possible.
+func (a *Aggregator) cancelPreemptedProof(prover proverInterface, proofID string) {
+	if err := prover.CancelProofRequest(proofID); err != nil {
+		log.Warnf("Failed to cancel preempted proof %s on prover %s: %v", proofID, prover.Name(), err)
+	}
+}
+
+// preemptForFinalProof periodically checks if the final proof is late because
+// all the provers are busy, preempting a batch proof when that is the case.
+func (a *Aggregator) preemptForFinalProof() {
+	ticker := time.NewTicker(a.cfg.Preemption.CheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.tryPreemptForFinalProof()
+		}
+	}
+}
+
+// tryPreemptForFinalProof cancels the lowest priority batch proof, the one of
+// the highest batch number, when the time to send a final proof has been
+// reached, there is a proof ready to be verified and no prover is available
+// to build the final proof. The batch is requeued as its proof is deleted.
+func (a *Aggregator) tryPreemptForFinalProof() {
+	if a.halted.Load() || !a.canVerifyProof() {
+		return
+	}
+	if !a.allProversBusy() {
+		return
+	}
+
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		log.Warnf("Failed to get last verified batch to check preemption: %v", err)
+		return
+	}
+	if _, err := a.state.GetProofReadyToVerify(a.ctx, lastVerifiedBatchNumber, nil); err != nil {
+		if !errors.Is(err, state.ErrNotFound) {
+			log.Warnf("Failed to get proof ready to verify to check preemption: %v", err)
+		}
+		return
+	}
+
+	a.proverJobsMutex.Lock()
+	defer a.proverJobsMutex.Unlock()
+
+	var victim *proverJob
+	for _, job := range a.proverJobs {
+		if job.kind == finalProofJob || job.preempted {
+			// a final proof is already being built or a prover is being freed for it
+			return
+		}
+		if job.kind != batchProofJob {
+			continue
+		}
+		if victim == nil || job.batchNumber > victim.batchNumber {
+			victim = job
+		}
+	}
+	if victim == nil {
+		log.Debug("Final proof is due but no batch proof can be preempted")
+		return
+	}
+
+	victim.preempted = true
+	victim.cancel()
+	metrics.PreemptedProof()
+	log.Infof("Preempting batch %d proof on prover %s, running for %v, to build the final proof",
+		victim.batchNumber, victim.proverName, time.Since(victim.startedAt))
+}
+
+// allProversBusy returns true if every connected prover is working on a job.
+func (a *Aggregator) allProversBusy() bool {
+	a.proverJobsMutex.Lock()
+	defer a.proverJobsMutex.Unlock()
+	connected := a.connectedProvers.Load()
+	return connected > 0 && int64(len(a.proverJobs)) >= connected
+}
@@ -26,12 +26,32 @@ var (
 	ErrUnspecified          = errors.New("Prover returned an UNSPECIFIED response")  //nolint:revive
 	ErrUnknown              = errors.New("Prover returned an unknown response")      //nolint:revive
 	ErrProofCanceled        = errors.New("Proof has been canceled")                  //nolint:revive
+	// ErrProverResourceExhausted is returned, together with the error of the
+	// response, when the prover failed because it ran out of resources
+	ErrProverResourceExhausted = errors.New("Prover ran out of resources") //nolint:revive
 )
 
+// resourceExhaustedMarkers are the texts of the result strings reported by the
+// provers when they run out of resources
+var resourceExhaustedMarkers = []string{"out of memory", "resource exhausted", "resource_exhausted", "bad_alloc"}
+
+// withResourceExhausted wraps the error with ErrProverResourceExhausted if the
+// result string of the response reports that the prover ran out of resources.
+func withResourceExhausted(err error, resultString string) error {
+	resultString = strings.ToLower(resultString)
+	for _, marker := range resourceExhaustedMarkers {
+		if strings.Contains(resultString, marker) {
+			return fmt.Errorf("%w: %w", ErrProverResourceExhausted, err)
+		}
+	}
+	return err
+}
+
 // Prover abstraction of the grpc prover client.
 type Prover struct {
 	name                      string
 	id                        string
+	version                   string
 	address                   net.Addr
 	proofStatePollingInterval types.Duration
 	stream                    AggregatorService_ChannelServer
@@ -50,6 +70,7 @@ func New(stream AggregatorService_ChannelServer, addr net.Addr, proofStatePollin
 	}
 	p.name = status.ProverName
 	p.id = status.ProverId
+	p.version = status.VersionServer
 	return p, nil
 }
 
@@ -59,6 +80,9 @@ func (p *Prover) Name() string { return p.name }
 // ID returns the Prover ID.
 func (p *Prover) ID() string { return p.id }
 
+// Version returns the server version reported by the prover.
+func (p *Prover) Version() string { return p.version }
+
 // Addr returns the prover IP address.
 func (p *Prover) Addr() string {
 	if p.address == nil {
@@ -316,11 +340,11 @@ func (p *Prover) waitProof(ctx context.Context, proofID string) (*GetProofRespon
 					return nil, fmt.Errorf("failed to get proof with ID %s, %w, prover response: %s",
 						proofID, ErrBadRequest, msg.GetProofResponse.String())
 				case GetProofResponse_RESULT_COMPLETED_ERROR:
-					return nil, fmt.Errorf("failed to get proof with ID %s, %w, prover response: %s",
-						proofID, ErrProverCompletedError, msg.GetProofResponse.String())
+					return nil, withResourceExhausted(fmt.Errorf("failed to get proof with ID %s, %w, prover response: %s",
+						proofID, ErrProverCompletedError, msg.GetProofResponse.String()), msg.GetProofResponse.ResultString)
 				case GetProofResponse_RESULT_INTERNAL_ERROR:
-					return nil, fmt.Errorf("failed to get proof ID: %s, %w, prover response: %s",
-						proofID, ErrProverInternalError, msg.GetProofResponse.String())
+					return nil, withResourceExhausted(fmt.Errorf("failed to get proof ID: %s, %w, prover response: %s",
+						proofID, ErrProverInternalError, msg.GetProofResponse.String()), msg.GetProofResponse.ResultString)
 				case GetProofResponse_RESULT_CANCEL:
 					return nil, fmt.Errorf("proof generation was cancelled for proof ID %s, %w, prover response: %s",
 						proofID, ErrProofCanceled, msg.GetProofResponse.String())
@@ -0,0 +1,113 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+)
+
+// UnknownProverPolicy is what the aggregator does with the provers not
+// declared in the configuration
+type UnknownProverPolicy string
+
+const (
+	// UnknownProverAccept gives jobs to the unknown provers
+	UnknownProverAccept UnknownProverPolicy = "accept"
+	// UnknownProverReject closes the channel of the unknown provers
+	UnknownProverReject UnknownProverPolicy = "reject"
+	// UnknownProverQuarantine keeps the unknown provers connected without
+	// giving them any job
+	UnknownProverQuarantine UnknownProverPolicy = "quarantine"
+
+	// ProverLabelBatch allows a labeled prover to generate batch proofs
+	ProverLabelBatch = "batch"
+	// ProverLabelAggregation allows a labeled prover to aggregate proofs
+	ProverLabelAggregation = "aggregation"
+	// ProverLabelFinal allows a labeled prover to build final proofs
+	ProverLabelFinal = "final"
+)
+
+// ErrProverNotAllowed is returned when a prover not declared in the
+// configuration connects and unknown provers are rejected.
+var ErrProverNotAllowed = errors.New("prover not allowed")
+
+// proverCfg returns the configuration of the prover, matched by name and, if
+// configured, by id.
+func (a *Aggregator) proverCfg(prover proverInterface) *ProverCfg {
+	for i := range a.cfg.Provers.Allowed {
+		cfg := &a.cfg.Provers.Allowed[i]
+		if cfg.Name == prover.Name() && (cfg.ID == "" || cfg.ID == prover.ID()) {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// admitProver applies the unknown provers policy to a prover that has just
+// connected, returning if it must be quarantined.
+func (a *Aggregator) admitProver(prover proverInterface) (bool, error) {
+	if len(a.cfg.Provers.Allowed) == 0 || a.proverCfg(prover) != nil {
+		return false, nil
+	}
+
+	switch a.cfg.Provers.UnknownPolicy {
+	case UnknownProverReject:
+		metrics.RejectedProver()
+		return false, fmt.Errorf("%w: %s (%s)", ErrProverNotAllowed, prover.Name(), prover.ID())
+	case UnknownProverQuarantine:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// proverAllowsJob returns true if the labels of the prover allow it to work on
+// the given kind of job. Provers without job kind labels can do any job.
+func (a *Aggregator) proverAllowsJob(prover proverInterface, kind proverJobKind) bool {
+	cfg := a.proverCfg(prover)
+	if cfg == nil {
+		return true
+	}
+
+	var want string
+	switch kind {
+	case batchProofJob:
+		want = ProverLabelBatch
+	case aggregatedProofJob:
+		want = ProverLabelAggregation
+	case finalProofJob:
+		want = ProverLabelFinal
+	}
+
+	restricted := false
+	for _, label := range cfg.Labels {
+		switch label {
+		case want:
+			return true
+		case ProverLabelBatch, ProverLabelAggregation, ProverLabelFinal:
+			restricted = true
+		}
+	}
+	return !restricted
+}
+
+// proverAtCapacity returns true if the provers sharing the name of the given
+// one are already working on the maximum number of concurrent jobs configured
+// for it.
+func (a *Aggregator) proverAtCapacity(prover proverInterface) bool {
+	cfg := a.proverCfg(prover)
+	if cfg == nil || cfg.MaxConcurrentJobs <= 0 {
+		return false
+	}
+
+	a.proverJobsMutex.Lock()
+	defer a.proverJobsMutex.Unlock()
+	jobs := 0
+	for _, job := range a.proverJobs {
+		if job.proverName == cfg.Name {
+			jobs++
+		}
+	}
+	return jobs >= cfg.MaxConcurrentJobs
+}
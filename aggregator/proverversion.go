@@ -0,0 +1,73 @@
+package aggregator
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// proverVersionsCompatible returns true if proofs generated by the given
+// prover versions can be aggregated together. Versions are compatible when
+// equal or listed in the same group of ProverVersions.Compatible. Unknown
+// versions, of proofs stored before the versions were recorded, are assumed
+// compatible.
+func (a *Aggregator) proverVersionsCompatible(version1, version2 string) bool {
+	if version1 == "" || version2 == "" || version1 == version2 {
+		return true
+	}
+	for _, group := range a.cfg.ProverVersions.Compatible {
+		var has1, has2 bool
+		for _, version := range group {
+			has1 = has1 || version == version1
+			has2 = has2 || version == version2
+		}
+		if has1 && has2 {
+			return true
+		}
+	}
+	return false
+}
+
+func proofProverVersion(proof *state.Proof) string {
+	if proof.ProverVersion == nil {
+		return ""
+	}
+	return *proof.ProverVersion
+}
+
+// checkProofsVersions returns true if the prover can aggregate the proofs. When
+// the proofs are not compatible with each other, the one not compatible with
+// the version of the prover is deleted so its batches are proven again by a
+// compatible prover. Otherwise the proofs are left to a compatible prover.
+// It must be called holding stateDBMutex.
+func (a *Aggregator) checkProofsVersions(ctx context.Context, prover proverInterface, proof1, proof2 *state.Proof) (bool, error) {
+	if !a.cfg.ProverVersions.Enabled {
+		return true, nil
+	}
+
+	version := prover.Version()
+	version1, version2 := proofProverVersion(proof1), proofProverVersion(proof2)
+	compatible1 := a.proverVersionsCompatible(version, version1)
+	compatible2 := a.proverVersionsCompatible(version, version2)
+	if compatible1 && compatible2 && a.proverVersionsCompatible(version1, version2) {
+		return true, nil
+	}
+
+	if compatible1 == compatible2 {
+		log.Debugf("Leaving proofs %d-%d (%s) and %d-%d (%s) to a prover compatible with them",
+			proof1.BatchNumber, proof1.BatchNumberFinal, version1, proof2.BatchNumber, proof2.BatchNumberFinal, version2)
+		return false, nil
+	}
+
+	incompatible, incompatibleVersion := proof1, version1
+	if compatible1 {
+		incompatible, incompatibleVersion = proof2, version2
+	}
+	log.Warnf("Proof %d-%d generated by prover version %s can not be aggregated with prover version %s, deleting it to prove its batches again",
+		incompatible.BatchNumber, incompatible.BatchNumberFinal, incompatibleVersion, version)
+	if err := a.state.DeleteGeneratedProofs(ctx, incompatible.BatchNumber, incompatible.BatchNumberFinal, nil); err != nil {
+		return false, err
+	}
+	return false, nil
+}
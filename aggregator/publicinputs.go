@@ -0,0 +1,61 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// finalProofPublicInputs gathers the public inputs the final proof of the
+// batch range has been built with. The old roots and the acc input hashes are
+// read from L1, where they are checked when the proof is verified, the new
+// roots are the ones the proof is settled with.
+func (a *Aggregator) finalProofPublicInputs(ctx context.Context, proof *state.Proof, inputs ethmanTypes.FinalProofInputs) (*state.FinalProofPublicInputs, error) {
+	oldStateRoot, err := a.etherman.GetBatchStateRoot(ctx, proof.BatchNumber-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the state root of batch %d from L1: %w", proof.BatchNumber-1, err)
+	}
+	oldAccInputHash, err := a.etherman.GetBatchAccInputHash(ctx, proof.BatchNumber-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the acc input hash of batch %d from L1: %w", proof.BatchNumber-1, err)
+	}
+	newAccInputHash, err := a.etherman.GetBatchAccInputHash(ctx, proof.BatchNumberFinal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the acc input hash of batch %d from L1: %w", proof.BatchNumberFinal, err)
+	}
+
+	return &state.FinalProofPublicInputs{
+		BatchNumber:      proof.BatchNumber,
+		BatchNumberFinal: proof.BatchNumberFinal,
+		ProofID:          proof.ProofID,
+		OldStateRoot:     oldStateRoot,
+		NewStateRoot:     common.BytesToHash(inputs.NewStateRoot),
+		OldAccInputHash:  oldAccInputHash,
+		NewAccInputHash:  newAccInputHash,
+		NewLocalExitRoot: common.BytesToHash(inputs.NewLocalExitRoot),
+		ChainID:          a.cfg.ChainID,
+		ForkID:           a.cfg.ForkId,
+		AggregatorAddr:   common.HexToAddress(a.cfg.SenderAddress),
+	}, nil
+}
+
+// recordFinalProofPublicInputs persists the public inputs of the final proof
+// before it is settled. Failing to record them does not hold the settlement.
+func (a *Aggregator) recordFinalProofPublicInputs(ctx context.Context, proof *state.Proof, inputs ethmanTypes.FinalProofInputs) {
+	log := log.WithFields("batches", fmt.Sprintf("%d-%d", proof.BatchNumber, proof.BatchNumberFinal))
+
+	publicInputs, err := a.finalProofPublicInputs(ctx, proof, inputs)
+	if err != nil {
+		log.Warnf("Failed to gather the final proof public inputs: %v", err)
+		return
+	}
+	if err := a.state.AddFinalProofPublicInputs(ctx, publicInputs, nil); err != nil {
+		log.Warnf("Failed to store the final proof public inputs: %v", err)
+		return
+	}
+	log.Debugf("Final proof public inputs recorded, old state root %s, new state root %s", publicInputs.OldStateRoot, publicInputs.NewStateRoot)
+}
@@ -0,0 +1,64 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/jackc/pgx/v4"
+)
+
+// Namespaces of the batch range locks shared by the aggregator replicas using
+// the same database.
+const (
+	sequenceLockNamespace   = "sequence"
+	finalProofLockNamespace = "finalproof"
+)
+
+// errFinalProofRangeLocked is used when the final proof for a batch range is
+// being built by another replica
+var errFinalProofRangeLocked = errors.New("final proof batch range locked by another aggregator")
+
+// tryLockFinalProofRange acquires the lock of the batch range of a final proof,
+// so no other replica builds or sends a final proof for the same range. The
+// lock is held until the proof verification ends.
+func (a *Aggregator) tryLockFinalProofRange(ctx context.Context, batchNumber, batchNumberFinal uint64) (bool, error) {
+	dbTx, err := a.state.BeginStateTransaction(ctx)
+	if err != nil {
+		return false, err
+	}
+	acquired, err := a.state.TryLockBatchRange(ctx, finalProofLockNamespace, batchNumber, batchNumberFinal, dbTx)
+	if err != nil || !acquired {
+		releaseBatchRangeLock(dbTx)
+		return false, err
+	}
+
+	a.timeSendFinalProofMutex.Lock()
+	previous := a.finalProofRangeLock
+	a.finalProofRangeLock = dbTx
+	a.timeSendFinalProofMutex.Unlock()
+	if previous != nil {
+		releaseBatchRangeLock(previous)
+	}
+	return true, nil
+}
+
+// releaseFinalProofRangeLock releases the lock of the batch range of the final
+// proof being built or verified, if any.
+func (a *Aggregator) releaseFinalProofRangeLock() {
+	a.timeSendFinalProofMutex.Lock()
+	dbTx := a.finalProofRangeLock
+	a.finalProofRangeLock = nil
+	a.timeSendFinalProofMutex.Unlock()
+	if dbTx != nil {
+		releaseBatchRangeLock(dbTx)
+	}
+}
+
+// releaseBatchRangeLock ends the transaction holding a batch range lock. The
+// lock is released by postgres when the transaction ends.
+func releaseBatchRangeLock(dbTx pgx.Tx) {
+	if err := dbTx.Rollback(context.Background()); err != nil {
+		log.Warnf("Failed to release batch range lock: %v", err)
+	}
+}
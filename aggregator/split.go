@@ -0,0 +1,76 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// aggregationSplit limits the number of batches of the aggregated proofs after
+// a prover runs out of resources aggregating a large range, so the range is
+// aggregated in smaller steps before retrying it whole.
+type aggregationSplit struct {
+	mutex sync.Mutex
+	// maxBatches is the maximum number of batches of an aggregated proof, 0
+	// when not limited
+	maxBatches uint64
+	// failedBatches is the number of batches of the aggregation that ran out
+	// of resources
+	failedBatches uint64
+}
+
+// splitAggregationOnResourceExhausted halves the number of batches of the
+// aggregated proofs when the aggregation of the given proof ran out of
+// resources in the prover. Batch proofs can not be split, they are retried as
+// any other failed proof.
+func (a *Aggregator) splitAggregationOnResourceExhausted(proof *state.Proof, err error) {
+	if !errors.Is(err, prover.ErrProverResourceExhausted) {
+		return
+	}
+	batches := proof.BatchNumberFinal - proof.BatchNumber + 1
+	if batches <= 2 { //nolint:gomnd
+		log.Warnf("Prover ran out of resources aggregating batches %d-%d, too few batches to split", proof.BatchNumber, proof.BatchNumberFinal)
+		return
+	}
+
+	a.aggregationSplit.mutex.Lock()
+	defer a.aggregationSplit.mutex.Unlock()
+	maxBatches := batches / 2 //nolint:gomnd
+	if a.aggregationSplit.maxBatches == 0 || maxBatches < a.aggregationSplit.maxBatches {
+		a.aggregationSplit.maxBatches = maxBatches
+	}
+	if batches > a.aggregationSplit.failedBatches {
+		a.aggregationSplit.failedBatches = batches
+	}
+	log.Warnf("Prover ran out of resources aggregating batches %d-%d, aggregating at most %d batches at once",
+		proof.BatchNumber, proof.BatchNumberFinal, a.aggregationSplit.maxBatches)
+}
+
+// getProofsToAggregate returns the next proofs to aggregate within the batch
+// limit set after a prover ran out of resources. When there is nothing left to
+// aggregate within the limit, the limit is doubled, until the size of the
+// aggregation that failed is reached and the limit is lifted.
+func (a *Aggregator) getProofsToAggregate(ctx context.Context) (*state.Proof, *state.Proof, error) {
+	a.aggregationSplit.mutex.Lock()
+	defer a.aggregationSplit.mutex.Unlock()
+
+	for {
+		proof1, proof2, err := a.state.GetProofsToAggregate(ctx, a.aggregationSplit.maxBatches, nil)
+		if !errors.Is(err, state.ErrNotFound) || a.aggregationSplit.maxBatches == 0 {
+			return proof1, proof2, err
+		}
+
+		a.aggregationSplit.maxBatches *= 2
+		if a.aggregationSplit.maxBatches >= a.aggregationSplit.failedBatches {
+			a.aggregationSplit.maxBatches = 0
+			a.aggregationSplit.failedBatches = 0
+			log.Info("Retrying the aggregations without batch limit")
+		} else {
+			log.Infof("Aggregating at most %d batches at once", a.aggregationSplit.maxBatches)
+		}
+	}
+}
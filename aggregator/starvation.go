@@ -0,0 +1,44 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// aggregationStarvationLimit returns the highest batch number a new batch proof
+// can be generated for without delaying the aggregation of the proof that has
+// been waiting the longest. While that proof has been waiting for less than
+// StarvationGuard.MaxAggregationWait there is no limit. Once it starves, only
+// the batches up to the end of its sequence, or its right neighbour, are
+// proven, so the provers that become idle are kept for the aggregations that
+// move the frontier forward instead of being handed newer batches.
+func (a *Aggregator) aggregationStarvationLimit(ctx context.Context, lastVerifiedBatchNumber uint64) (uint64, bool, error) {
+	proof, err := a.state.GetOldestProofToAggregate(ctx, lastVerifiedBatchNumber, nil)
+	if errors.Is(err, state.ErrNotFound) {
+		metrics.OldestProofToAggregate(0)
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	waiting := time.Since(proof.UpdatedAt)
+	metrics.OldestProofToAggregate(waiting)
+	if waiting < a.cfg.StarvationGuard.MaxAggregationWait.Duration {
+		return 0, false, nil
+	}
+
+	limit := proof.BatchNumberFinal + 1
+	sequence, err := a.l1Syncr.GetSequenceByBatchNumber(ctx, proof.BatchNumberFinal)
+	if err != nil {
+		return 0, false, err
+	}
+	if sequence != nil && sequence.ToBatchNumber > limit {
+		limit = sequence.ToBatchNumber
+	}
+	return limit, true, nil
+}
@@ -0,0 +1,198 @@
+package aggregator
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/datastream"
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamProtocol is a version of the data stream protocol served by the
+// sequencer
+type StreamProtocol uint8
+
+const (
+	// StreamProtocolAuto negotiates the protocol from the stream header
+	StreamProtocolAuto StreamProtocol = 0
+	// StreamProtocolLegacy is the binary protocol of the first stream
+	// servers: L2 block and tx entries only and bookmarks by L2 block
+	StreamProtocolLegacy StreamProtocol = 1
+	// StreamProtocolV2 is the protobuf protocol: batch start and end entries
+	// and bookmarks by batch
+	StreamProtocolV2 StreamProtocol = 2
+
+	// streamFileVersionV2 is the first stream file version served with the v2
+	// protocol
+	streamFileVersionV2 = 3
+)
+
+// entry types of the legacy protocol
+const (
+	legacyEntryTypeL2BlockStart datastreamer.EntryType = 1
+	legacyEntryTypeL2Tx         datastreamer.EntryType = 2
+	legacyEntryTypeL2BlockEnd   datastreamer.EntryType = 3
+
+	legacyL2BlockStartLength = 122
+	legacyL2TxHeaderLength   = 38
+	legacyL2BlockEndLength   = 72
+)
+
+// legacyStreamState is the batch being rebuilt from a legacy stream, whose
+// entries do not delimit the batches
+type legacyStreamState struct {
+	// batches up to this one are skipped, the legacy protocol can not start
+	// streaming from a batch
+	skipUpTo    uint64
+	batchNumber uint64
+	stateRoot   common.Hash
+}
+
+// negotiateStreamProtocol returns the configured data stream protocol, or the
+// one matching the version of the stream served by the sequencer.
+func (a *Aggregator) negotiateStreamProtocol() (StreamProtocol, error) {
+	switch StreamProtocol(a.cfg.StreamClient.Protocol) {
+	case StreamProtocolLegacy, StreamProtocolV2:
+		return StreamProtocol(a.cfg.StreamClient.Protocol), nil
+	case StreamProtocolAuto:
+	default:
+		return 0, fmt.Errorf("unsupported data stream protocol %d", a.cfg.StreamClient.Protocol)
+	}
+
+	header, err := a.streamClient.ExecCommandGetHeader()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get data stream header: %w", err)
+	}
+	if header.Version < streamFileVersionV2 {
+		return StreamProtocolLegacy, nil
+	}
+	return StreamProtocolV2, nil
+}
+
+// startStreaming starts receiving the data stream from the given batch.
+func (a *Aggregator) startStreaming(batchNumber uint64) error {
+	protocol, err := a.negotiateStreamProtocol()
+	if err != nil {
+		return err
+	}
+	a.streamProtocol = protocol
+	log.Infof("Using data stream protocol %d", protocol)
+
+	if protocol == StreamProtocolLegacy {
+		// there are no batch bookmarks, stream everything and skip the
+		// batches already verified
+		a.legacyStream = legacyStreamState{skipUpTo: batchNumber - 1}
+		return a.streamClient.ExecCommandStart(0)
+	}
+
+	bookMark := &datastream.BookMark{
+		Type:  datastream.BookmarkType_BOOKMARK_TYPE_BATCH,
+		Value: batchNumber,
+	}
+	marshalledBookMark, err := proto.Marshal(bookMark)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bookmark: %w", err)
+	}
+	return a.streamClient.ExecCommandStartBookmark(marshalledBookMark)
+}
+
+// handleLegacyStreamEntry translates the entries of the legacy protocol to
+// the v2 ones. A batch is closed when the first L2 block of the next one is
+// received, so the last streamed batch is stored one batch late. The legacy
+// protocol does not carry the local exit root of the batches.
+func (a *Aggregator) handleLegacyStreamEntry(entry *datastreamer.FileEntry) error {
+	switch entry.Type {
+	case legacyEntryTypeL2BlockStart:
+		if len(entry.Data) < legacyL2BlockStartLength {
+			return fmt.Errorf("invalid legacy L2 block start entry length %d", len(entry.Data))
+		}
+		d := entry.Data
+		batchNumber := binary.BigEndian.Uint64(d[0:8])
+		if batchNumber <= a.legacyStream.skipUpTo {
+			return nil
+		}
+
+		if batchNumber != a.legacyStream.batchNumber {
+			if a.legacyStream.batchNumber != 0 {
+				err := a.processLegacyEntry(datastream.EntryType_ENTRY_TYPE_BATCH_END, &datastream.BatchEnd{
+					Number:    a.legacyStream.batchNumber,
+					StateRoot: a.legacyStream.stateRoot.Bytes(),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			batchType := datastream.BatchType_BATCH_TYPE_REGULAR
+			if batchNumber == 1 {
+				batchType = datastream.BatchType_BATCH_TYPE_INJECTED
+			}
+			err := a.processLegacyEntry(datastream.EntryType_ENTRY_TYPE_BATCH_START, &datastream.BatchStart{
+				Number:  batchNumber,
+				Type:    batchType,
+				ForkId:  uint64(binary.BigEndian.Uint16(d[116:118])),
+				ChainId: uint64(binary.BigEndian.Uint32(d[118:122])),
+			})
+			if err != nil {
+				return err
+			}
+			a.legacyStream.batchNumber = batchNumber
+		}
+
+		return a.processLegacyEntry(datastream.EntryType_ENTRY_TYPE_L2_BLOCK, &datastream.L2Block{
+			Number:          binary.BigEndian.Uint64(d[8:16]),
+			BatchNumber:     batchNumber,
+			Timestamp:       binary.BigEndian.Uint64(d[16:24]),
+			DeltaTimestamp:  binary.BigEndian.Uint32(d[24:28]),
+			L1InfotreeIndex: binary.BigEndian.Uint32(d[28:32]),
+			L1Blockhash:     d[32:64],
+			GlobalExitRoot:  d[64:96],
+			Coinbase:        d[96:116],
+		})
+
+	case legacyEntryTypeL2Tx:
+		if a.legacyStream.batchNumber == 0 {
+			return nil
+		}
+		if len(entry.Data) < legacyL2TxHeaderLength {
+			return fmt.Errorf("invalid legacy L2 tx entry length %d", len(entry.Data))
+		}
+		d := entry.Data
+		encodedLength := binary.BigEndian.Uint32(d[34:38])
+		if uint64(len(d)) < legacyL2TxHeaderLength+uint64(encodedLength) {
+			return fmt.Errorf("invalid legacy L2 tx entry length %d for encoded tx of %d bytes", len(d), encodedLength)
+		}
+		return a.processLegacyEntry(datastream.EntryType_ENTRY_TYPE_TRANSACTION, &datastream.Transaction{
+			EffectiveGasPricePercentage: uint32(d[0]),
+			IsValid:                     d[1] != 0,
+			ImStateRoot:                 d[2:34],
+			Encoded:                     d[legacyL2TxHeaderLength : legacyL2TxHeaderLength+encodedLength],
+		})
+
+	case legacyEntryTypeL2BlockEnd:
+		if a.legacyStream.batchNumber == 0 {
+			return nil
+		}
+		if len(entry.Data) < legacyL2BlockEndLength {
+			return fmt.Errorf("invalid legacy L2 block end entry length %d", len(entry.Data))
+		}
+		a.legacyStream.stateRoot = common.BytesToHash(entry.Data[40:72])
+	}
+
+	return nil
+}
+
+// processLegacyEntry processes a v2 entry translated from the legacy protocol.
+func (a *Aggregator) processLegacyEntry(entryType datastream.EntryType, msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return a.processStreamEntry(&datastreamer.FileEntry{
+		Type:   datastreamer.EntryType(entryType),
+		Length: uint32(len(data)) + datastreamer.FixedSizeFileEntry,
+		Data:   data,
+	})
+}
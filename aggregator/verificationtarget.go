@@ -0,0 +1,243 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/proxy"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-ethtx-manager/ethtxmanager"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// VerificationTargetHTTP posts the final proof as JSON to an HTTP endpoint,
+	// e.g. an attestation service
+	VerificationTargetHTTP = "http"
+	// VerificationTargetEVM sends the final proof to a contract exposing the
+	// RollupManager verifyBatchesTrustedAggregator method, e.g. a mirror
+	// contract on another chain
+	VerificationTargetEVM = "evm"
+
+	verificationTargetMaxAttempts    = 5
+	verificationTargetDefaultTimeout = time.Minute
+)
+
+// VerifiedFinalProof is the final proof sent to the secondary verification
+// targets once it has been verified by the primary settlement.
+type VerifiedFinalProof struct {
+	RollupID         uint32      `json:"rollupId"`
+	BatchNumber      uint64      `json:"batchNumber"`
+	BatchNumberFinal uint64      `json:"batchNumberFinal"`
+	NewStateRoot     common.Hash `json:"newStateRoot"`
+	NewLocalExitRoot common.Hash `json:"newLocalExitRoot"`
+	Proof            string      `json:"proof"`
+	// SettlementTxHash is the hash of the tx that verified the proof on the
+	// primary settlement
+	SettlementTxHash common.Hash `json:"settlementTxHash"`
+}
+
+// verificationTarget is a secondary destination of the verified final proofs
+type verificationTarget interface {
+	name() string
+	submit(ctx context.Context, proof *VerifiedFinalProof, calldata []byte) error
+}
+
+// newVerificationTargets creates the secondary verification targets from
+// their configuration.
+func newVerificationTargets(cfgs []VerificationTargetCfg, proxyCfg proxy.Config) ([]verificationTarget, error) {
+	targets := make([]verificationTarget, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Timeout.Duration == 0 {
+			cfg.Timeout.Duration = verificationTargetDefaultTimeout
+		}
+		switch cfg.Type {
+		case VerificationTargetHTTP:
+			client, err := proxyCfg.HTTPClient(cfg.Timeout.Duration)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, &httpVerificationTarget{
+				cfg:    cfg,
+				client: client,
+			})
+		case VerificationTargetEVM:
+			rpcClient, err := proxyCfg.DialRPC(context.Background(), cfg.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to verification target %s: %w", cfg.Name, err)
+			}
+			client := ethclient.NewClient(rpcClient)
+			key, err := newKeyFromKeystore(cfg.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the key of verification target %s: %w", cfg.Name, err)
+			}
+			if key == nil {
+				return nil, fmt.Errorf("verification target %s requires a private key", cfg.Name)
+			}
+			targets = append(targets, &evmVerificationTarget{cfg: cfg, client: client, key: key})
+		default:
+			return nil, fmt.Errorf("verification target %s has unknown type %q", cfg.Name, cfg.Type)
+		}
+	}
+	return targets, nil
+}
+
+// submitToVerificationTargets sends a final proof verified by the primary
+// settlement to every secondary target. Failures are retried but never affect
+// the primary settlement.
+func (a *Aggregator) submitToVerificationTargets(proof *state.Proof, inputs ethmanTypes.FinalProofInputs, settlementTxHash common.Hash) {
+	if len(a.verificationTargets) == 0 {
+		return
+	}
+
+	verified := &VerifiedFinalProof{
+		RollupID:         a.etherman.GetRollupId(),
+		BatchNumber:      proof.BatchNumber,
+		BatchNumberFinal: proof.BatchNumberFinal,
+		NewStateRoot:     common.BytesToHash(inputs.NewStateRoot),
+		NewLocalExitRoot: common.BytesToHash(inputs.NewLocalExitRoot),
+		Proof:            inputs.FinalProof.Proof,
+		SettlementTxHash: settlementTxHash,
+	}
+	// the targets exposing the RollupManager interface receive the same
+	// calldata as the L1 verification
+	_, calldata, err := a.etherman.BuildTrustedVerifyBatchesTxData(proof.BatchNumber-1, proof.BatchNumberFinal, &inputs, a.beneficiary(proof))
+	if err != nil {
+		log.Errorf("Failed to build the calldata for the verification targets: %v", err)
+		return
+	}
+
+	for _, target := range a.verificationTargets {
+		go func(target verificationTarget) {
+			log := log.WithFields("target", target.name(), "batches", fmt.Sprintf("%d-%d", verified.BatchNumber, verified.BatchNumberFinal))
+			for attempt := 1; attempt <= verificationTargetMaxAttempts; attempt++ {
+				err := target.submit(a.ctx, verified, calldata)
+				if err == nil {
+					log.Info("Final proof submitted to verification target")
+					return
+				}
+				log.Warnf("Failed to submit final proof to verification target, attempt %d/%d: %v", attempt, verificationTargetMaxAttempts, err)
+
+				select {
+				case <-a.ctx.Done():
+					return
+				case <-time.After(a.cfg.RetryTime.Duration):
+				}
+			}
+			log.Error("Giving up submitting final proof to verification target")
+		}(target)
+	}
+}
+
+// minedTxHash returns the hash of the mined tx of a monitored tx.
+func minedTxHash(result ethtxmanager.MonitoredTxResult) common.Hash {
+	for txHash, txResult := range result.Txs {
+		if txResult.Receipt != nil && txResult.Receipt.Status == types.ReceiptStatusSuccessful {
+			return txHash
+		}
+	}
+	return common.Hash{}
+}
+
+type httpVerificationTarget struct {
+	cfg    VerificationTargetCfg
+	client *http.Client
+}
+
+func (t *httpVerificationTarget) name() string {
+	return t.cfg.Name
+}
+
+func (t *httpVerificationTarget) submit(ctx context.Context, proof *VerifiedFinalProof, _ []byte) error {
+	body, err := json.Marshal(proof)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+type evmVerificationTarget struct {
+	cfg    VerificationTargetCfg
+	client *ethclient.Client
+	key    *ecdsa.PrivateKey
+}
+
+func (t *evmVerificationTarget) name() string {
+	return t.cfg.Name
+}
+
+// submit sends the same calldata as the primary L1 verification to the target
+// contract and waits for it to be mined.
+func (t *evmVerificationTarget) submit(ctx context.Context, _ *VerifiedFinalProof, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, t.cfg.Timeout.Duration)
+	defer cancel()
+
+	from := crypto.PubkeyToAddress(t.key.PublicKey)
+	to := common.HexToAddress(t.cfg.ContractAddress)
+
+	chainID, err := t.client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	nonce, err := t.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := t.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	gas, err := t.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Data: data})
+	if err != nil {
+		return err
+	}
+
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Gas:      gas,
+		GasPrice: gasPrice,
+		Data:     data,
+	}), types.LatestSignerForChainID(chainID), t.key)
+	if err != nil {
+		return err
+	}
+	if err := t.client.SendTransaction(ctx, tx); err != nil && !isKnownTxError(err) {
+		return err
+	}
+
+	receipt, err := bind.WaitMined(ctx, t.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("tx %s reverted", tx.Hash())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+)
+
+// initRollupVerifier records the verifier the final proofs are settled
+// against, to detect when it is rotated.
+func (a *Aggregator) initRollupVerifier(ctx context.Context) error {
+	verifier, err := a.etherman.GetRollupVerifier(ctx)
+	if err != nil {
+		return err
+	}
+	if verifier.ForkID != a.cfg.ForkId {
+		log.Warnf("Rollup fork ID on L1 is %d but the aggregator is configured with fork ID %d", verifier.ForkID, a.cfg.ForkId)
+	}
+	log.Infof("Rollup verifier: %s, fork ID: %d, rollup type: %d", verifier.Verifier, verifier.ForkID, verifier.RollupTypeID)
+	a.rollupVerifier = verifier
+	metrics.VerifierChanged(false)
+	return nil
+}
+
+// watchRollupVerifier periodically checks if the verifier of the rollup has
+// been changed, either directly or by upgrading the rollup type. The proofs
+// built for the old verifier would be rejected by L1, so the submission of
+// final proofs is paused until the aggregator is restarted with provers for
+// the new verifier.
+func (a *Aggregator) watchRollupVerifier() {
+	ticker := time.NewTicker(a.cfg.VerifierCheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			verifier, err := a.etherman.GetRollupVerifier(a.ctx)
+			if err != nil {
+				log.Warnf("Failed to get rollup verifier: %v", err)
+				continue
+			}
+			if *verifier == *a.rollupVerifier {
+				continue
+			}
+
+			a.verifierChanged.Store(true)
+			metrics.VerifierChanged(true)
+			log.Errorf("Rollup verifier changed from %s (fork ID %d, rollup type %d) to %s (fork ID %d, rollup type %d). "+
+				"Final proof submission is paused, restart the aggregator with provers for the new verifier and delete the stored proofs",
+				a.rollupVerifier.Verifier, a.rollupVerifier.ForkID, a.rollupVerifier.RollupTypeID,
+				verifier.Verifier, verifier.ForkID, verifier.RollupTypeID)
+			return
+		}
+	}
+}
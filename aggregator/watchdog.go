@@ -0,0 +1,185 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+// watchdogMaxProofs is the maximum number of proofs read by the watchdog to
+// detect the changes of the pipeline
+const watchdogMaxProofs = 10000
+
+// pipelineWatchdog keeps the last observed state of the proving pipeline and
+// the prover channels it can close.
+type pipelineWatchdog struct {
+	fingerprint [sha256.Size]byte
+	changedAt   time.Time
+
+	mutex    sync.Mutex
+	channels map[*context.CancelFunc]struct{}
+}
+
+func newPipelineWatchdog() *pipelineWatchdog {
+	return &pipelineWatchdog{
+		changedAt: time.Now(),
+		channels:  make(map[*context.CancelFunc]struct{}),
+	}
+}
+
+// watchChannel returns a context of the prover channel the watchdog cancels to
+// make the prover reconnect, and the function to call when the channel ends.
+func (a *Aggregator) watchChannel(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	a.watchdog.mutex.Lock()
+	a.watchdog.channels[&cancel] = struct{}{}
+	a.watchdog.mutex.Unlock()
+
+	return ctx, func() {
+		a.watchdog.mutex.Lock()
+		delete(a.watchdog.channels, &cancel)
+		a.watchdog.mutex.Unlock()
+		cancel()
+	}
+}
+
+// watchPipeline checks the proving pipeline until the aggregator stops,
+// reporting a stall when it does not change for StallTimeout while there are
+// batches to prove.
+func (a *Aggregator) watchPipeline() {
+	ticker := time.NewTicker(a.cfg.Watchdog.CheckInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.checkPipeline(a.ctx); err != nil {
+				log.Errorf("Failed to check the proving pipeline: %v", err)
+			}
+		}
+	}
+}
+
+// checkPipeline compares the last verified batch and the proofs in progress
+// with the previous check, diagnosing and remediating the stall once the
+// pipeline has not changed for StallTimeout.
+func (a *Aggregator) checkPipeline(ctx context.Context) error {
+	lastVerifiedBatchNumber, err := a.etherman.GetLatestVerifiedBatchNum()
+	if err != nil {
+		return fmt.Errorf("failed to get the last verified batch: %w", err)
+	}
+	unproven, err := a.state.GetUnprovenBatchNumbers(ctx, lastVerifiedBatchNumber, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the unproven batches: %w", err)
+	}
+	proofs, err := a.state.GetProofs(ctx, lastVerifiedBatchNumber+1, watchdogMaxProofs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the proofs: %w", err)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d;", lastVerifiedBatchNumber)
+	for _, proof := range proofs {
+		fmt.Fprintf(hash, "%d-%d:%v:%d;", proof.BatchNumber, proof.BatchNumberFinal, proof.GeneratingSince != nil, proof.UpdatedAt.UnixNano())
+	}
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+
+	if fingerprint != a.watchdog.fingerprint {
+		a.watchdog.fingerprint = fingerprint
+		a.watchdog.changedAt = time.Now()
+		return nil
+	}
+
+	stalledFor := time.Since(a.watchdog.changedAt)
+	if (len(unproven) == 0 && len(proofs) == 0) || stalledFor < a.cfg.Watchdog.StallTimeout.Duration {
+		return nil
+	}
+
+	metrics.PipelineStalled()
+	log.Errorf("Proving pipeline stalled for %s at verified batch %d with %d unproven batches and %d proofs",
+		stalledFor.Round(time.Second), lastVerifiedBatchNumber, len(unproven), len(proofs))
+
+	if a.cfg.Watchdog.DiagnosticsDir != "" {
+		if err := a.writeStallDiagnostics(proofs); err != nil {
+			log.Errorf("Failed to write the stall diagnostics: %v", err)
+		}
+	}
+	a.remediateStall(ctx)
+
+	// wait for another StallTimeout before acting again
+	a.watchdog.changedAt = time.Now()
+	return nil
+}
+
+// writeStallDiagnostics writes the goroutine dump and the proofs in progress
+// to the diagnostics directory.
+func (a *Aggregator) writeStallDiagnostics(proofs []state.Proof) error {
+	dir := a.cfg.Watchdog.DiagnosticsDir
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gomnd
+		return err
+	}
+	suffix := time.Now().UTC().Format("20060102T150405Z")
+
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil { //nolint:gomnd
+		return fmt.Errorf("failed to dump the goroutines: %w", err)
+	}
+	goroutinesFile := filepath.Join(dir, "goroutines-"+suffix+".txt")
+	if err := os.WriteFile(goroutinesFile, goroutines.Bytes(), 0o644); err != nil { //nolint:gomnd
+		return err
+	}
+
+	stuck := make([]state.Proof, 0, len(proofs))
+	for _, proof := range proofs {
+		if proof.GeneratingSince != nil {
+			stuck = append(stuck, proof)
+		}
+	}
+	b, err := json.MarshalIndent(stuck, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize the proofs in progress: %w", err)
+	}
+	proofsFile := filepath.Join(dir, "proofs-"+suffix+".json")
+	if err := os.WriteFile(proofsFile, b, 0o644); err != nil { //nolint:gomnd
+		return err
+	}
+
+	log.Warnf("Stall diagnostics written to %s and %s", goroutinesFile, proofsFile)
+	return nil
+}
+
+// remediateStall applies the configured remediation actions.
+func (a *Aggregator) remediateStall(ctx context.Context) {
+	if a.cfg.Watchdog.ResetGeneratingProofs {
+		n, err := a.state.CleanupLockedProofs(ctx, "0s", nil)
+		if err != nil {
+			log.Errorf("Failed to reset the proofs in progress: %v", err)
+		} else {
+			log.Warnf("Watchdog reset %d proofs in progress", n)
+		}
+	}
+
+	if a.cfg.Watchdog.ReconnectProvers {
+		a.watchdog.mutex.Lock()
+		for cancel := range a.watchdog.channels {
+			(*cancel)()
+		}
+		n := len(a.watchdog.channels)
+		a.watchdog.mutex.Unlock()
+		log.Warnf("Watchdog closed the channels of %d provers to make them reconnect", n)
+	}
+}
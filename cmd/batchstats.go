@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/config"
+	"github.com/0xPolygonHermez/zkevm-aggregator/db"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/pgstatestorage"
+	"github.com/urfave/cli/v2"
+)
+
+// batchStatsMetric is a batch size or complexity metric correlated with the
+// proving time
+type batchStatsMetric struct {
+	name  string
+	value func(stats state.BatchStats) float64
+}
+
+var batchStatsMetrics = []batchStatsMetric{
+	{"witness size (bytes)", func(s state.BatchStats) float64 { return float64(s.WitnessSize) }},
+	{"l2 data size (bytes)", func(s state.BatchStats) float64 { return float64(s.L2DataSize) }},
+	{"blocks", func(s state.BatchStats) float64 { return float64(s.BlockCount) }},
+	{"txs", func(s state.BatchStats) float64 { return float64(s.TxCount) }},
+	{"tx gas limit", func(s state.BatchStats) float64 { return float64(s.GasLimit) }},
+}
+
+// batchStatsReport prints the batch size and complexity metrics recorded when
+// proving the batches, and their correlation with the proving time.
+func batchStatsReport(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, false)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Aggregator.Log)
+
+	sqlDB, err := db.NewSQLDB(c.Aggregator.DB)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	storage := pgstatestorage.NewPostgresStorage(state.Config{DB: c.Aggregator.DB}, sqlDB)
+
+	toBatchNumber := uint64(math.MaxInt64)
+	if cliCtx.IsSet(statsToBatchFlag.Name) {
+		toBatchNumber = cliCtx.Uint64(statsToBatchFlag.Name)
+	}
+	batchStats, err := storage.GetBatchStats(cliCtx.Context, cliCtx.Uint64(statsFromBatchFlag.Name), toBatchNumber, nil)
+	if err != nil {
+		return err
+	}
+	if len(batchStats) == 0 {
+		fmt.Println("No batch stats recorded in the range")
+		return nil
+	}
+
+	provingTimes := make([]float64, len(batchStats))
+	for i, stats := range batchStats {
+		provingTimes[i] = stats.ProvingTime.Seconds()
+	}
+
+	fmt.Printf("Batches %d-%d, %d proven\n\n", batchStats[0].BatchNumber, batchStats[len(batchStats)-1].BatchNumber, len(batchStats))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	fmt.Fprintln(w, "METRIC\tMIN\tAVG\tMAX\tCORRELATION WITH PROVING TIME")
+	fmt.Fprintf(w, "proving time (s)\t%.1f\t%.1f\t%.1f\t-\n", minOf(provingTimes), mean(provingTimes), maxOf(provingTimes))
+	for _, metric := range batchStatsMetrics {
+		values := make([]float64, len(batchStats))
+		for i, stats := range batchStats {
+			values[i] = metric.value(stats)
+		}
+		fmt.Fprintf(w, "%s\t%.0f\t%.1f\t%.0f\t%s\n", metric.name, minOf(values), mean(values), maxOf(values), formatCorrelation(values, provingTimes))
+	}
+	return w.Flush()
+}
+
+// formatCorrelation returns the Pearson correlation coefficient of x and y,
+// or "n/a" if it is not defined because one of them is constant.
+func formatCorrelation(x, y []float64) string {
+	meanX, meanY := mean(x), mean(y)
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.3f", cov/math.Sqrt(varX*varY))
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func minOf(values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Min(result, v)
+	}
+	return result
+}
+
+func maxOf(values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Max(result, v)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator"
+	"github.com/urfave/cli/v2"
+)
+
+const injectFinalProofTimeout = time.Minute
+
+func injectFinalProof(cliCtx *cli.Context) error {
+	proofFile := cliCtx.String(proofFileFlag.Name)
+	data, err := os.ReadFile(proofFile) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to read proof file %s: %w", proofFile, err)
+	}
+
+	var injected aggregator.InjectedFinalProof
+	if err := json.Unmarshal(data, &injected); err != nil {
+		return fmt.Errorf("failed to parse proof file %s: %w", proofFile, err)
+	}
+	if cliCtx.IsSet(fromBatchFlag.Name) {
+		injected.BatchNumber = cliCtx.Uint64(fromBatchFlag.Name)
+	}
+	if cliCtx.IsSet(toBatchFlag.Name) {
+		injected.BatchNumberFinal = cliCtx.Uint64(toBatchFlag.Name)
+	}
+
+	body, err := json.Marshal(injected)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(cliCtx.String(adminURLFlag.Name), "/") + aggregator.AdminFinalProofsEndpoint
+	req, err := http.NewRequestWithContext(cliCtx.Context, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := cliCtx.String(adminAPIKeyFlag.Name); apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	client := &http.Client{Timeout: injectFinalProofTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send final proof to %s: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("final proof rejected (%s): %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	fmt.Printf("Final proof for batches %d-%d accepted\n", injected.BatchNumber, injected.BatchNumberFinal)
+	return nil
+}
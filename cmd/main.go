@@ -35,6 +35,60 @@ var (
 		Usage:    "Load the network configuration file if --network=custom",
 		Required: false,
 	}
+	profileFlag = cli.StringFlag{
+		Name:     config.FlagProfile,
+		Usage:    "Configuration profile to apply on top of the configuration file, e.g. [`mainnet`, `testnet`, `dev`]",
+		Required: false,
+	}
+	adminURLFlag = cli.StringFlag{
+		Name:  "admin-url",
+		Usage: "URL of the aggregator admin API",
+		Value: "http://localhost:50082",
+	}
+	adminAPIKeyFlag = cli.StringFlag{
+		Name:    "api-key",
+		Usage:   "Key to authenticate to the aggregator admin API",
+		EnvVars: []string{"ZKEVM_AGGREGATOR_ADMIN_API_KEY"},
+	}
+	proofFileFlag = cli.StringFlag{
+		Name:     "proof-file",
+		Usage:    "JSON `FILE` containing the final proof and its public outputs",
+		Required: true,
+	}
+	fromBatchFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First batch number verified by the proof, overrides the value in the proof file",
+	}
+	toBatchFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last batch number verified by the proof, overrides the value in the proof file",
+	}
+	statsFromBatchFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First batch number of the report",
+	}
+	statsToBatchFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last batch number of the report",
+	}
+	forkURLFlag = cli.StringFlag{
+		Name:  "fork-url",
+		Usage: "`URL` of an L1 fork, e.g. Anvil or Hardhat, to run the simulation on instead of the configured L1 node",
+	}
+	senderFlag = cli.StringFlag{
+		Name:  "sender",
+		Usage: "`ADDRESS` the verification tx is simulated from, defaults to the configured sender",
+	}
+	traceFlag = cli.BoolFlag{
+		Name:  "trace",
+		Usage: "Trace the call with debug_traceCall, requires the debug namespace to be enabled in the node",
+	}
+	snapshotFileFlag = cli.StringFlag{
+		Name:     "file",
+		Aliases:  []string{"f"},
+		Usage:    "Snapshot `FILE`",
+		Required: true,
+	}
 )
 
 func main() {
@@ -56,7 +110,47 @@ func main() {
 			Aliases: []string{},
 			Usage:   "Run the zkevm-aggregator",
 			Action:  start,
-			Flags:   append(flags, &networkFlag, &customNetworkFlag),
+			Flags:   append(flags, &networkFlag, &customNetworkFlag, &profileFlag),
+		},
+		{
+			Name:    "inject-final-proof",
+			Aliases: []string{},
+			Usage:   "Send a final proof produced out-of-band to a running aggregator to be settled",
+			Action:  injectFinalProof,
+			Flags:   []cli.Flag{&adminURLFlag, &adminAPIKeyFlag, &proofFileFlag, &fromBatchFlag, &toBatchFlag},
+		},
+		{
+			Name:    "simulate-verify",
+			Aliases: []string{},
+			Usage:   "Run the verification tx of a final proof against the current L1 state, or a fork of it, and print the revert reason and trace",
+			Action:  simulateVerify,
+			Flags: append(flags, &networkFlag, &customNetworkFlag, &profileFlag, &proofFileFlag, &fromBatchFlag, &toBatchFlag,
+				&forkURLFlag, &senderFlag, &traceFlag),
+		},
+		{
+			Name:    "batch-stats",
+			Aliases: []string{},
+			Usage:   "Report the witness size and complexity of the proven batches and their correlation with the proving time",
+			Action:  batchStatsReport,
+			Flags:   append(flags, &profileFlag, &statsFromBatchFlag, &statsToBatchFlag),
+		},
+		{
+			Name:  "state",
+			Usage: "Manage the aggregator state",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "export",
+					Usage:  "Export a snapshot of the batches, sequences, proofs and monitored txs",
+					Action: exportState,
+					Flags:  append(flags, &profileFlag, &snapshotFileFlag),
+				},
+				{
+					Name:   "import",
+					Usage:  "Import a snapshot into an empty database",
+					Action: importState,
+					Flags:  append(flags, &profileFlag, &snapshotFileFlag),
+				},
+			},
 		},
 	}
 
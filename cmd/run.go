@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-aggregator"
@@ -22,7 +23,9 @@ import (
 	"github.com/0xPolygonHermez/zkevm-aggregator/log"
 	"github.com/0xPolygonHermez/zkevm-aggregator/metrics"
 	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/memstatestorage"
 	"github.com/0xPolygonHermez/zkevm-aggregator/state/pgstatestorage"
+	xlayermetrics "github.com/0xPolygonHermez/zkevm-aggregator/xlayer/metrics"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
@@ -42,17 +45,32 @@ func start(cliCtx *cli.Context) error {
 		logVersion()
 	}
 
+	if err := c.Proxy.Validate(); err != nil {
+		return err
+	}
+	if c.Proxy.URL != "" {
+		log.Infof("Routing the outgoing traffic of the aggregator through proxy %s", c.Proxy.Redacted())
+	}
+	c.Aggregator.Proxy = c.Proxy
+
 	if c.Metrics.Enabled {
 		metrics.Init()
+		xlayermetrics.Register(zkevm.GitRev, zkevm.ForkVersion, zkevm.UpstreamVersion)
 	}
 
-	// Migrations
-	if !cliCtx.Bool(config.FlagMigrations) {
-		log.Infof("Running DB migrations host: %s:%s db:%s user:%s", c.Aggregator.DB.Host, c.Aggregator.DB.Port, c.Aggregator.DB.Name, c.Aggregator.DB.User)
-		runAggregatorMigrations(c.Aggregator.DB)
-	}
+	inMemoryState := c.Aggregator.DB.Engine == db.EngineMemory
+	if inMemoryState {
+		log.Warn("Using the in-memory state storage, nothing will be persisted across restarts")
+	} else {
+		// Migrations
+		if !cliCtx.Bool(config.FlagMigrations) {
+			log.Infof("Running DB migrations host: %s:%s db:%s user:%s", c.Aggregator.DB.Host, c.Aggregator.DB.Port, c.Aggregator.DB.Name, c.Aggregator.DB.User)
+			runAggregatorMigrations(c.Aggregator.DB)
+		}
 
-	checkAggregatorMigrations(c.Aggregator.DB)
+		checkAggregatorMigrations(c.Aggregator.DB)
+		checkAggregatorSchema(c.Aggregator.DB)
+	}
 
 	var (
 		eventLog     *event.EventLog
@@ -74,9 +92,12 @@ func start(cliCtx *cli.Context) error {
 	eventLog = event.NewEventLog(c.EventLog, eventStorage)
 
 	// Core State DB
-	stateSqlDB, err := db.NewSQLDB(c.Aggregator.DB)
-	if err != nil {
-		log.Fatal(err)
+	var stateSqlDB *pgxpool.Pool
+	if !inMemoryState {
+		stateSqlDB, err = db.NewSQLDB(c.Aggregator.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	etherman, err := newEtherman(*c)
@@ -90,6 +111,16 @@ func start(cliCtx *cli.Context) error {
 		log.Fatal(err)
 	}
 
+	if err := checkChainIDs(cliCtx.Context, c, etherman, l2ChainID); err != nil {
+		log.Fatal(err)
+	}
+
+	if c.Etherman.WSURL != "" {
+		if err := etherman.StartSubscriptions(cliCtx.Context); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	st := newState(c, l2ChainID, stateSqlDB, eventLog)
 
 	c.Aggregator.ChainID = l2ChainID
@@ -142,6 +173,13 @@ func checkAggregatorMigrations(c db.Config) {
 	}
 }
 
+func checkAggregatorSchema(c db.Config) {
+	err := db.CheckSchema(c, db.AggregatorMigrationName, db.AggregatorSchemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 func runMigrations(c db.Config, name string) {
 	log.Infof("running migrations for %v", name)
 	err := db.RunMigrationsUp(c, name)
@@ -152,11 +190,38 @@ func runMigrations(c db.Config, name string) {
 
 func newEtherman(c config.Config) (*etherman.Client, error) {
 	config := etherman.Config{
-		URL: c.Aggregator.EthTxManager.Etherman.URL,
+		URL:                 c.Aggregator.EthTxManager.Etherman.URL,
+		WSURL:               c.Etherman.WSURL,
+		FilterLogsChunkSize: c.Etherman.FilterLogsChunkSize,
+		Proxy:               c.Proxy,
 	}
 	return etherman.NewClient(config, c.NetworkConfig.L1Config)
 }
 
+// checkChainIDs verifies the L1 node is on the chain of the network config and
+// the rollup contracts report the configured L2 chain, so a config pointed at
+// the wrong network fails at startup instead of sending txs to it.
+func checkChainIDs(ctx context.Context, c *config.Config, etherman *etherman.Client, l2ChainID uint64) error {
+	l1ChainID, err := etherman.GetL1ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the chain id of the L1 node: %w", err)
+	}
+	if l1ChainID != c.NetworkConfig.L1Config.L1ChainID {
+		return fmt.Errorf("the L1 node %s is on chain %d but the network config expects chain %d",
+			c.Aggregator.EthTxManager.Etherman.URL, l1ChainID, c.NetworkConfig.L1Config.L1ChainID)
+	}
+	if expected := c.Aggregator.EthTxManager.Etherman.L1ChainID; expected != 0 && l1ChainID != expected {
+		return fmt.Errorf("the L1 node %s is on chain %d but the eth tx manager is configured for chain %d",
+			c.Aggregator.EthTxManager.Etherman.URL, l1ChainID, expected)
+	}
+	if c.Aggregator.ChainID != 0 && l2ChainID != c.Aggregator.ChainID {
+		return fmt.Errorf("the rollup %s reports L2 chain %d but the config expects chain %d",
+			c.NetworkConfig.L1Config.ZkEVMAddr, l2ChainID, c.Aggregator.ChainID)
+	}
+	log.Infof("Chain ids checked, L1: %d, L2: %d", l1ChainID, l2ChainID)
+	return nil
+}
+
 func runAggregator(ctx context.Context, config aggregator.Config, etherman *etherman.Client, st *state.State) {
 	agg, err := aggregator.New(ctx, config, st, etherman)
 	if err != nil {
@@ -192,7 +257,12 @@ func newState(c *config.Config, l2ChainID uint64, sqlDB *pgxpool.Pool, eventLog
 		ChainID: l2ChainID,
 	}
 
-	stateDb := pgstatestorage.NewPostgresStorage(stateCfg, sqlDB)
+	var stateDb state.Storage
+	if sqlDB == nil {
+		stateDb = memstatestorage.NewMemoryStorage(stateCfg)
+	} else {
+		stateDb = pgstatestorage.NewPostgresStorage(stateCfg, sqlDB)
+	}
 
 	st := state.NewState(stateCfg, stateDb, eventLog)
 	return st
@@ -201,7 +271,7 @@ func newState(c *config.Config, l2ChainID uint64, sqlDB *pgxpool.Pool, eventLog
 func startProfilingHttpServer(c metrics.Config) {
 	const two = 2
 	mux := http.NewServeMux()
-	address := fmt.Sprintf("%s:%d", c.ProfilingHost, c.ProfilingPort)
+	address := net.JoinHostPort(c.ProfilingHost, strconv.Itoa(c.ProfilingPort))
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Errorf("failed to create tcp listener for profiling: %v", err)
@@ -231,7 +301,7 @@ func startProfilingHttpServer(c metrics.Config) {
 func startMetricsHttpServer(c metrics.Config) {
 	const ten = 10
 	mux := http.NewServeMux()
-	address := fmt.Sprintf("%s:%d", c.Host, c.Port)
+	address := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Errorf("failed to create tcp listener for metrics: %v", err)
@@ -260,6 +330,8 @@ func logVersion() {
 		// node version is already logged by default
 		"gitRevision", zkevm.GitRev,
 		"gitBranch", zkevm.GitBranch,
+		"forkVersion", zkevm.ForkVersion,
+		"upstreamVersion", zkevm.UpstreamVersion,
 		"goVersion", runtime.Version(),
 		"built", zkevm.BuildDate,
 		"os/arch", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator"
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/config"
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// simulateVerify builds the verification tx of a final proof and runs it
+// against the current L1 state, or a fork of it, printing the revert reason
+// and the call trace instead of sending it.
+func simulateVerify(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, true)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Aggregator.Log)
+
+	proofFile := cliCtx.String(proofFileFlag.Name)
+	data, err := os.ReadFile(proofFile) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to read proof file %s: %w", proofFile, err)
+	}
+	var proof aggregator.InjectedFinalProof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return fmt.Errorf("failed to parse proof file %s: %w", proofFile, err)
+	}
+	if cliCtx.IsSet(fromBatchFlag.Name) {
+		proof.BatchNumber = cliCtx.Uint64(fromBatchFlag.Name)
+	}
+	if cliCtx.IsSet(toBatchFlag.Name) {
+		proof.BatchNumberFinal = cliCtx.Uint64(toBatchFlag.Name)
+	}
+	if proof.BatchNumber == 0 || proof.BatchNumberFinal < proof.BatchNumber {
+		return fmt.Errorf("invalid batch range %d-%d", proof.BatchNumber, proof.BatchNumberFinal)
+	}
+
+	if forkURL := cliCtx.String(forkURLFlag.Name); forkURL != "" {
+		c.Aggregator.EthTxManager.Etherman.URL = forkURL
+	}
+	etherman, err := newEtherman(*c)
+	if err != nil {
+		return err
+	}
+
+	sender := common.HexToAddress(c.Aggregator.SenderAddress)
+	if cliCtx.IsSet(senderFlag.Name) {
+		sender = common.HexToAddress(cliCtx.String(senderFlag.Name))
+	}
+
+	inputs := ethmanTypes.FinalProofInputs{
+		FinalProof:       &prover.FinalProof{Proof: proof.Proof},
+		NewLocalExitRoot: proof.NewLocalExitRoot.Bytes(),
+		NewStateRoot:     proof.NewStateRoot.Bytes(),
+	}
+	to, calldata, err := etherman.BuildTrustedVerifyBatchesTxData(proof.BatchNumber-1, proof.BatchNumberFinal, &inputs, sender)
+	if err != nil {
+		return fmt.Errorf("failed to build verify batches calldata: %w", err)
+	}
+
+	simulation, err := etherman.SimulateCall(cliCtx.Context, sender, to, calldata, cliCtx.Bool(traceFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Batches:  %d-%d\n", proof.BatchNumber, proof.BatchNumberFinal)
+	fmt.Printf("From:     %s\n", sender)
+	fmt.Printf("To:       %s\n", to)
+	fmt.Printf("Calldata: %#x\n", calldata)
+	if simulation.Reverted {
+		fmt.Printf("Result:   reverted: %s\n", simulation.RevertReason)
+	} else {
+		fmt.Printf("Result:   success, gas %d\n", simulation.GasUsed)
+	}
+	if simulation.Trace != nil {
+		trace, err := json.MarshalIndent(simulation.Trace, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Trace:\n%s\n", trace)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/config"
+	"github.com/0xPolygonHermez/zkevm-aggregator/db"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/pgstatestorage"
+	"github.com/jackc/pgx/v4"
+	"github.com/urfave/cli/v2"
+)
+
+const snapshotFilePerm = 0o600
+
+// exportState writes a snapshot of the aggregator state to a file. It can be
+// run while the aggregator is running.
+func exportState(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, false)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Aggregator.Log)
+
+	sqlDB, err := db.NewSQLDB(c.Aggregator.DB)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	storage := pgstatestorage.NewPostgresStorage(state.Config{DB: c.Aggregator.DB}, sqlDB)
+
+	ctx := cliCtx.Context
+	dbTx, err := sqlDB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dbTx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Warnf("Failed to rollback snapshot transaction: %v", err)
+		}
+	}()
+
+	snapshot, err := storage.ExportSnapshot(ctx, dbTx)
+	if err != nil {
+		return fmt.Errorf("failed to export state: %w", err)
+	}
+
+	if filename := c.Aggregator.EthTxManager.PersistenceFilename; filename != "" {
+		monitoredTxs, err := os.ReadFile(filename) //nolint:gosec
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read monitored txs from %s: %w", filename, err)
+		}
+		if len(monitoredTxs) > 0 {
+			snapshot.MonitoredTxs = monitoredTxs
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	snapshotFile := cliCtx.String(snapshotFileFlag.Name)
+	if err := os.WriteFile(snapshotFile, data, snapshotFilePerm); err != nil {
+		return fmt.Errorf("failed to write snapshot to %s: %w", snapshotFile, err)
+	}
+
+	log.Infof("State exported to %s: %d batches, %d sequences, %d proofs, monitored txs included: %t",
+		snapshotFile, len(snapshot.Batches), len(snapshot.Sequences), len(snapshot.Proofs), snapshot.MonitoredTxs != nil)
+	return nil
+}
+
+// importState loads a snapshot into an empty aggregator database. The
+// aggregator must not be running.
+func importState(cliCtx *cli.Context) error {
+	c, err := config.Load(cliCtx, false)
+	if err != nil {
+		return err
+	}
+	setupLog(c.Aggregator.Log)
+
+	snapshotFile := cliCtx.String(snapshotFileFlag.Name)
+	data, err := os.ReadFile(snapshotFile) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot from %s: %w", snapshotFile, err)
+	}
+	var snapshot state.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot %s: %w", snapshotFile, err)
+	}
+
+	runAggregatorMigrations(c.Aggregator.DB)
+
+	sqlDB, err := db.NewSQLDB(c.Aggregator.DB)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	storage := pgstatestorage.NewPostgresStorage(state.Config{DB: c.Aggregator.DB}, sqlDB)
+
+	ctx := cliCtx.Context
+	dbTx, err := sqlDB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := storage.ImportSnapshot(ctx, &snapshot, dbTx); err != nil {
+		if errRollback := dbTx.Rollback(ctx); errRollback != nil {
+			log.Errorf("Failed to rollback snapshot import: %v", errRollback)
+		}
+		return fmt.Errorf("failed to import state: %w", err)
+	}
+
+	if filename := c.Aggregator.EthTxManager.PersistenceFilename; filename != "" && snapshot.MonitoredTxs != nil {
+		if _, err := os.Stat(filename); err == nil {
+			if errRollback := dbTx.Rollback(ctx); errRollback != nil {
+				log.Errorf("Failed to rollback snapshot import: %v", errRollback)
+			}
+			return fmt.Errorf("monitored txs file %s already exists", filename)
+		}
+		if err := os.WriteFile(filename, snapshot.MonitoredTxs, snapshotFilePerm); err != nil {
+			if errRollback := dbTx.Rollback(ctx); errRollback != nil {
+				log.Errorf("Failed to rollback snapshot import: %v", errRollback)
+			}
+			return fmt.Errorf("failed to write monitored txs to %s: %w", filename, err)
+		}
+	} else if snapshot.MonitoredTxs != nil {
+		log.Warn("Snapshot contains monitored txs but EthTxManager.PersistenceFilename is not set, skipping them")
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Infof("State imported from %s: %d batches, %d sequences, %d proofs",
+		snapshotFile, len(snapshot.Batches), len(snapshot.Sequences), len(snapshot.Proofs))
+	return nil
+}
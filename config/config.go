@@ -10,6 +10,7 @@ import (
 	"github.com/0xPolygonHermez/zkevm-aggregator/event"
 	"github.com/0xPolygonHermez/zkevm-aggregator/log"
 	"github.com/0xPolygonHermez/zkevm-aggregator/metrics"
+	"github.com/0xPolygonHermez/zkevm-aggregator/proxy"
 	"github.com/0xPolygonHermez/zkevm-ethtx-manager/ethtxmanager"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
@@ -33,6 +34,8 @@ const (
 	FlagMigrations = "migrations"
 	// FlagDocumentationFileType is the flag for the choose which file generate json-schema
 	FlagDocumentationFileType = "config-file"
+	// FlagProfile is the flag for the configuration profile to apply, e.g. [profile.mainnet]
+	FlagProfile = "profile"
 )
 
 /*
@@ -59,6 +62,8 @@ type Config struct {
 	Metrics metrics.Config
 	// Configuration of the event database connection
 	EventLog event.Config
+	// Configuration of the proxy for the outgoing HTTP traffic
+	Proxy proxy.Config
 }
 
 // Default parses the default configuration values.
@@ -109,6 +114,14 @@ func Load(ctx *cli.Context, loadNetworkConfig bool) (*Config, error) {
 		}
 	}
 
+	if profile := ctx.String(FlagProfile); profile != "" {
+		log.Infof("applying configuration profile %s", profile)
+		err = applyProfile(viper.GetViper(), profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	decodeHooks := []viper.DecoderConfigOption{
 		// this allows arrays to be decoded from env var separated by ",", example: MY_VAR="value1,value2,value3"
 		viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(mapstructure.TextUnmarshallerHookFunc(), mapstructure.StringToSliceHookFunc(","))),
@@ -2,6 +2,9 @@ package config
 
 // DefaultValues is the default configuration
 const DefaultValues = `
+[Etherman]
+WSURL = ""
+FilterLogsChunkSize = 10000
 [Aggregator]
 Host = "0.0.0.0"
 Port = 50081
@@ -11,19 +14,28 @@ TxProfitabilityCheckerType = "acceptall"
 TxProfitabilityMinReward = "1.1"
 ProofStatePollingInterval = "5s"
 SenderAddress = ""
+L1PermissionsCheckInterval = "5m"
+BroadcastL1URLs = []
+VerificationTargets = []
+VerifierCheckInterval = "1m"
 CleanupLockedProofsInterval = "2m"
 GeneratingProofCleanupThreshold = "10m"
 BatchProofSanityCheckEnabled = true
+AccInputHashCacheSize = 10000
+AccInputHashCheckEnabled = true
+ExitRootCheckEnabled = true
 ForkId = 9
 GasOffset = 0
 WitnessURL = "localhost:8123"
 UseL1BatchData = true
 UseFullWitness = false
+BatchDataFallbackToL1 = false
 SettlementBackend = "l1"
 AggLayerTxTimeout = "5m"
 AggLayerURL = ""
 SequencerPrivateKey = {}
 	[Aggregator.DB]
+		Engine = "postgres"
 		Name = "aggregator_db"
 		User = "aggregator_user"
 		Password = "aggregator_password"
@@ -31,12 +43,65 @@ SequencerPrivateKey = {}
 		Port = "5432"
 		EnableLog = false	
 		MaxConns = 200
+		SlowQueryThreshold = "1s"
 	[Aggregator.Log]
 		Environment = "development" # "production" or "development"
 		Level = "info"
 		Outputs = ["stderr"]
 	[Aggregator.StreamClient]
 		Server = "localhost:6900"
+		Protocol = 0
+	[Aggregator.AdminAPI]
+		Enabled = false
+		Host = "0.0.0.0"
+		Port = 50082
+		APIKeys = []
+		ExplorerL1URL = ""
+	[Aggregator.KeepWarm]
+		Enabled = false
+		IdleInterval = "10m"
+	[Aggregator.Preemption]
+		Enabled = false
+		CheckInterval = "10s"
+	[Aggregator.Beneficiary]
+		Template = ""
+		Addresses = []
+	[Aggregator.Provers]
+		UnknownPolicy = "accept"
+		Allowed = []
+		SeparateRetryDomains = false
+	[Aggregator.StarvationGuard]
+		Enabled = false
+		MaxAggregationWait = "10m"
+	[Aggregator.ETA]
+		Window = 100
+		UpdateInterval = "1m"
+	[Aggregator.ProofPartitioning]
+		Enabled = false
+		BatchRange = 10000
+		Ahead = 1
+		CheckInterval = "10m"
+	[Aggregator.BatchMaturity]
+		MinAge = "0s"
+		MinL1Confirmations = 0
+	[Aggregator.FailedProofs]
+		CaptureEnabled = true
+		ExportURL = ""
+		ExportAuthorization = ""
+		ExportTimeout = "30s"
+	[Aggregator.Sequences]
+		Source = "synchronizer"
+		CheckInterval = "10s"
+	[Aggregator.Watchdog]
+		Enabled = false
+		CheckInterval = "1m"
+		StallTimeout = "30m"
+		DiagnosticsDir = ""
+		ResetGeneratingProofs = false
+		ReconnectProvers = false
+	[Aggregator.ProverVersions]
+		Enabled = false
+		Compatible = []
 	[Aggregator.EthTxManager]
 		FrequencyToMonitorTxs = "1s"
 		WaitTxToBeMined = "2m"
@@ -77,4 +142,7 @@ SequencerPrivateKey = {}
 				Enabled = false
 [EventLog]
 	[EventLog.DB]
+[Proxy]
+URL = ""
+NoProxy = ""
 `
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// profilesKey is the configuration section holding the profiles, e.g. [profile.mainnet]
+	profilesKey = "profile"
+	// profileInheritsKey is the key of a profile naming the profile it inherits from
+	profileInheritsKey = "inherits"
+)
+
+// applyProfile merges the values of the named profile on top of the loaded
+// configuration. Profiles can inherit from another profile using the
+// Inherits key, the inherited values are applied first so the most specific
+// profile wins.
+func applyProfile(v *viper.Viper, name string) error {
+	var chain []map[string]interface{}
+	visited := map[string]bool{}
+	for current := strings.ToLower(name); current != ""; {
+		if visited[current] {
+			return fmt.Errorf("circular inheritance found in configuration profile %q", current)
+		}
+		visited[current] = true
+
+		key := profilesKey + "." + current
+		if !v.IsSet(key) {
+			return fmt.Errorf("configuration profile %q not found", current)
+		}
+
+		profile := map[string]interface{}{}
+		for k, value := range v.GetStringMap(key) {
+			profile[k] = value
+		}
+		chain = append(chain, profile)
+
+		parent, _ := profile[profileInheritsKey].(string)
+		delete(profile, profileInheritsKey)
+		current = strings.ToLower(parent)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := v.MergeConfigMap(chain[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+const profilesTestConfig = `
+[Aggregator]
+Port = 50081
+RetryTime = "5s"
+ForkId = 9
+
+[profile.mainnet]
+	[profile.mainnet.Aggregator]
+	RetryTime = "10s"
+	ForkId = 11
+
+[profile.testnet]
+Inherits = "mainnet"
+	[profile.testnet.Aggregator]
+	Port = 50091
+
+[profile.loop]
+Inherits = "loop"
+`
+
+func TestApplyProfile(t *testing.T) {
+	load := func(t *testing.T) *viper.Viper {
+		v := viper.New()
+		v.SetConfigType("toml")
+		require.NoError(t, v.ReadConfig(bytes.NewBufferString(profilesTestConfig)))
+		return v
+	}
+
+	t.Run("single profile", func(t *testing.T) {
+		v := load(t)
+		require.NoError(t, applyProfile(v, "mainnet"))
+		require.Equal(t, 50081, v.GetInt("Aggregator.Port"))
+		require.Equal(t, "10s", v.GetString("Aggregator.RetryTime"))
+		require.Equal(t, 11, v.GetInt("Aggregator.ForkId"))
+	})
+
+	t.Run("inherited profile", func(t *testing.T) {
+		v := load(t)
+		require.NoError(t, applyProfile(v, "TestNet"))
+		require.Equal(t, 50091, v.GetInt("Aggregator.Port"))
+		require.Equal(t, "10s", v.GetString("Aggregator.RetryTime"))
+		require.Equal(t, 11, v.GetInt("Aggregator.ForkId"))
+		require.False(t, v.IsSet(profileInheritsKey))
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		require.Error(t, applyProfile(load(t), "dev"))
+	})
+
+	t.Run("circular inheritance", func(t *testing.T) {
+		require.Error(t, applyProfile(load(t), "loop"))
+	})
+}
@@ -1,7 +1,21 @@
 package db
 
+import "github.com/0xPolygonHermez/zkevm-aggregator/config/types"
+
+const (
+	// EnginePostgres stores the state in the configured Postgres database
+	EnginePostgres = "postgres"
+	// EngineMemory keeps the state in memory, nothing is persisted across
+	// restarts. Meant for tests and development setups
+	EngineMemory = "memory"
+)
+
 // Config provide fields to configure the pool
 type Config struct {
+	// Engine is the storage engine of the state, "postgres" or "memory". The
+	// connection fields are ignored by the memory engine
+	Engine string `mapstructure:"Engine"`
+
 	// Database name
 	Name string `mapstructure:"Name"`
 
@@ -22,4 +36,8 @@ type Config struct {
 
 	// MaxConns is the maximum number of connections in the pool.
 	MaxConns int `mapstructure:"MaxConns"`
+
+	// SlowQueryThreshold is the duration above which the storage queries are
+	// logged as slow. 0 disables the slow query log
+	SlowQueryThreshold types.Duration `mapstructure:"SlowQueryThreshold"`
 }
@@ -0,0 +1,14 @@
+-- +migrate Down
+DROP TABLE IF EXISTS aggregator.audit_log;
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS aggregator.audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	action varchar NOT NULL,
+	principal varchar NOT NULL,
+	params jsonb NULL,
+	result varchar NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_action_idx ON aggregator.audit_log (action, created_at);
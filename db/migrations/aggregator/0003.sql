@@ -0,0 +1,15 @@
+-- +migrate Down
+DROP TABLE IF EXISTS aggregator.batch_stats;
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS aggregator.batch_stats (
+	batch_num BIGINT PRIMARY KEY,
+	witness_size BIGINT NOT NULL,
+	l2_data_size BIGINT NOT NULL,
+	block_count BIGINT NOT NULL,
+	tx_count BIGINT NOT NULL,
+	gas_limit BIGINT NOT NULL,
+	proving_time_ms BIGINT NOT NULL,
+	prover varchar NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1,17 @@
+-- +migrate Down
+DROP TABLE IF EXISTS aggregator.l1_intent;
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS aggregator.l1_intent (
+	id BIGSERIAL PRIMARY KEY,
+	batch_num BIGINT NOT NULL,
+	batch_num_final BIGINT NOT NULL,
+	calldata_hash varchar NOT NULL,
+	nonce BIGINT NOT NULL,
+	monitored_tx_id varchar NULL,
+	status varchar NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS l1_intent_status_idx ON aggregator.l1_intent (status);
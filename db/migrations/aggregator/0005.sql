@@ -0,0 +1,19 @@
+-- +migrate Down
+DROP TABLE IF EXISTS aggregator.failed_proofs;
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS aggregator.failed_proofs (
+	id BIGSERIAL PRIMARY KEY,
+	kind varchar NOT NULL,
+	batch_num BIGINT NOT NULL,
+	batch_num_final BIGINT NOT NULL,
+	proof_id varchar NULL,
+	prover varchar NOT NULL,
+	prover_id varchar NOT NULL,
+	input_prover varchar NOT NULL,
+	error varchar NOT NULL,
+	exported_at TIMESTAMP WITH TIME ZONE NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS failed_proofs_batch_num_idx ON aggregator.failed_proofs (batch_num, batch_num_final);
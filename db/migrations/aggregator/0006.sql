@@ -0,0 +1,19 @@
+-- +migrate Down
+DROP TABLE IF EXISTS aggregator.final_proof_public_inputs;
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS aggregator.final_proof_public_inputs (
+	batch_num BIGINT NOT NULL,
+	batch_num_final BIGINT NOT NULL,
+	proof_id varchar NULL,
+	old_state_root varchar NOT NULL,
+	new_state_root varchar NOT NULL,
+	old_acc_input_hash varchar NOT NULL,
+	new_acc_input_hash varchar NOT NULL,
+	new_local_exit_root varchar NOT NULL,
+	chain_id BIGINT NOT NULL,
+	fork_id BIGINT NOT NULL,
+	aggregator_addr varchar NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	PRIMARY KEY (batch_num, batch_num_final)
+);
@@ -0,0 +1,52 @@
+-- +migrate Down
+ALTER TABLE aggregator.proof RENAME TO proof_partitioned;
+ALTER TABLE aggregator.proof_partitioned RENAME CONSTRAINT proof_pkey TO proof_partitioned_pkey;
+
+CREATE TABLE IF NOT EXISTS aggregator.proof (
+	batch_num BIGINT NOT NULL REFERENCES aggregator.batch (batch_num) ON DELETE CASCADE,
+	batch_num_final BIGINT NOT NULL,
+	proof varchar NULL,
+	proof_id varchar NULL,
+	input_prover varchar NULL,
+	prover varchar NULL,
+	prover_id varchar NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	generating_since timestamptz NULL,
+	PRIMARY KEY (batch_num, batch_num_final)
+);
+
+INSERT INTO aggregator.proof (batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, created_at, updated_at, generating_since)
+	SELECT batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, created_at, updated_at, generating_since
+	FROM aggregator.proof_partitioned;
+
+DROP TABLE aggregator.proof_partitioned;
+
+-- +migrate Up
+ALTER TABLE aggregator.proof RENAME TO proof_unpartitioned;
+ALTER TABLE aggregator.proof_unpartitioned RENAME CONSTRAINT proof_pkey TO proof_unpartitioned_pkey;
+
+-- proofs are partitioned by batch number range, the aggregator creates the
+-- partitions ahead of the proven batches and drops the verified ones. The
+-- default partition holds the proofs out of the created ranges.
+CREATE TABLE IF NOT EXISTS aggregator.proof (
+	batch_num BIGINT NOT NULL REFERENCES aggregator.batch (batch_num) ON DELETE CASCADE,
+	batch_num_final BIGINT NOT NULL,
+	proof varchar NULL,
+	proof_id varchar NULL,
+	input_prover varchar NULL,
+	prover varchar NULL,
+	prover_id varchar NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	generating_since timestamptz NULL,
+	PRIMARY KEY (batch_num, batch_num_final)
+) PARTITION BY RANGE (batch_num);
+
+CREATE TABLE IF NOT EXISTS aggregator.proof_default PARTITION OF aggregator.proof DEFAULT;
+
+INSERT INTO aggregator.proof (batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, created_at, updated_at, generating_since)
+	SELECT batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, created_at, updated_at, generating_since
+	FROM aggregator.proof_unpartitioned;
+
+DROP TABLE aggregator.proof_unpartitioned;
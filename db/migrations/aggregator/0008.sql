@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE aggregator.proof DROP COLUMN IF EXISTS prover_version;
+
+-- +migrate Up
+ALTER TABLE aggregator.proof ADD COLUMN IF NOT EXISTS prover_version varchar NULL;
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// AggregatorSchemaName is the postgres schema holding the aggregator tables
+const AggregatorSchemaName = "aggregator"
+
+// schemaColumn is a column expected by the binary, with the data type and
+// nullability reported by information_schema.columns
+type schemaColumn struct {
+	dataType string
+	nullable bool
+}
+
+// schemaTable is a table expected by the binary
+type schemaTable struct {
+	columns map[string]schemaColumn
+	indexes []string
+}
+
+// aggregatorSchema is the schema the aggregator queries rely on. It must be
+// kept in sync with the migrations.
+var aggregatorSchema = map[string]schemaTable{
+	"batch": {
+		columns: map[string]schemaColumn{
+			"batch_num":  {"bigint", false},
+			"batch":      {"jsonb", false},
+			"datastream": {"character varying", false},
+		},
+		indexes: []string{"batch_pkey"},
+	},
+	"proof": {
+		columns: map[string]schemaColumn{
+			"batch_num":        {"bigint", false},
+			"batch_num_final":  {"bigint", false},
+			"proof":            {"character varying", true},
+			"proof_id":         {"character varying", true},
+			"input_prover":     {"character varying", true},
+			"prover":           {"character varying", true},
+			"prover_id":        {"character varying", true},
+			"prover_version":   {"character varying", true},
+			"created_at":       {"timestamp with time zone", false},
+			"updated_at":       {"timestamp with time zone", false},
+			"generating_since": {"timestamp with time zone", true},
+		},
+		indexes: []string{"proof_pkey"},
+	},
+	"sequence": {
+		columns: map[string]schemaColumn{
+			"from_batch_num": {"bigint", false},
+			"to_batch_num":   {"bigint", false},
+		},
+		indexes: []string{"sequence_pkey"},
+	},
+	"audit_log": {
+		columns: map[string]schemaColumn{
+			"id":         {"bigint", false},
+			"action":     {"character varying", false},
+			"principal":  {"character varying", false},
+			"params":     {"jsonb", true},
+			"result":     {"character varying", false},
+			"created_at": {"timestamp with time zone", false},
+		},
+		indexes: []string{"audit_log_pkey", "audit_log_action_idx"},
+	},
+	"batch_stats": {
+		columns: map[string]schemaColumn{
+			"batch_num":       {"bigint", false},
+			"witness_size":    {"bigint", false},
+			"l2_data_size":    {"bigint", false},
+			"block_count":     {"bigint", false},
+			"tx_count":        {"bigint", false},
+			"gas_limit":       {"bigint", false},
+			"proving_time_ms": {"bigint", false},
+			"prover":          {"character varying", true},
+			"created_at":      {"timestamp with time zone", false},
+		},
+		indexes: []string{"batch_stats_pkey"},
+	},
+	"l1_intent": {
+		columns: map[string]schemaColumn{
+			"id":              {"bigint", false},
+			"batch_num":       {"bigint", false},
+			"batch_num_final": {"bigint", false},
+			"calldata_hash":   {"character varying", false},
+			"nonce":           {"bigint", false},
+			"monitored_tx_id": {"character varying", true},
+			"status":          {"character varying", false},
+			"created_at":      {"timestamp with time zone", false},
+			"updated_at":      {"timestamp with time zone", false},
+		},
+		indexes: []string{"l1_intent_pkey", "l1_intent_status_idx"},
+	},
+	"failed_proofs": {
+		columns: map[string]schemaColumn{
+			"id":              {"bigint", false},
+			"kind":            {"character varying", false},
+			"batch_num":       {"bigint", false},
+			"batch_num_final": {"bigint", false},
+			"proof_id":        {"character varying", true},
+			"prover":          {"character varying", false},
+			"prover_id":       {"character varying", false},
+			"input_prover":    {"character varying", false},
+			"error":           {"character varying", false},
+			"exported_at":     {"timestamp with time zone", true},
+			"created_at":      {"timestamp with time zone", false},
+		},
+		indexes: []string{"failed_proofs_pkey", "failed_proofs_batch_num_idx"},
+	},
+	"final_proof_public_inputs": {
+		columns: map[string]schemaColumn{
+			"batch_num":           {"bigint", false},
+			"batch_num_final":     {"bigint", false},
+			"proof_id":            {"character varying", true},
+			"old_state_root":      {"character varying", false},
+			"new_state_root":      {"character varying", false},
+			"old_acc_input_hash":  {"character varying", false},
+			"new_acc_input_hash":  {"character varying", false},
+			"new_local_exit_root": {"character varying", false},
+			"chain_id":            {"bigint", false},
+			"fork_id":             {"bigint", false},
+			"aggregator_addr":     {"character varying", false},
+			"created_at":          {"timestamp with time zone", false},
+		},
+		indexes: []string{"final_proof_public_inputs_pkey"},
+	},
+}
+
+// CheckSchema verifies that the migrations applied to the database are the
+// ones packed in the binary and that the tables, columns and indexes the
+// aggregator relies on exist with the expected types. All the differences
+// found are reported in the returned error.
+func CheckSchema(cfg Config, packrName string, schema string) error {
+	c, err := pgx.ParseConfig(fmt.Sprintf("postgres://%s:%s@%s:%s/%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name))
+	if err != nil {
+		return err
+	}
+	db := stdlib.OpenDB(*c)
+	defer db.Close()
+
+	box, ok := packrMigrations[packrName]
+	if !ok {
+		return fmt.Errorf("packr box not found with name: %v", packrName)
+	}
+	migrations, err := (&migrate.PackrMigrationSource{Box: box}).FindMigrations()
+	if err != nil {
+		return err
+	}
+
+	diffs, err := migrationsDiff(db, migrations)
+	if err != nil {
+		return err
+	}
+	tableDiffs, err := tablesDiff(db, schema, aggregatorSchema)
+	if err != nil {
+		return err
+	}
+	diffs = append(diffs, tableDiffs...)
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("database schema does not match the one expected by this binary:\n  %s", strings.Join(diffs, "\n  "))
+	}
+	log.Infof("Database schema %s matches the expected one", schema)
+	return nil
+}
+
+// migrationsDiff compares the applied migrations with the packed ones.
+func migrationsDiff(db *sql.DB, migrations []*migrate.Migration) ([]string, error) {
+	rows, err := db.Query(`SELECT id FROM public.gorp_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	for _, migration := range migrations {
+		if !applied[migration.Id] {
+			diffs = append(diffs, fmt.Sprintf("migration %s not applied", migration.Id))
+		}
+		delete(applied, migration.Id)
+	}
+	for _, id := range sortedKeys(applied) {
+		diffs = append(diffs, fmt.Sprintf("migration %s applied but unknown to this binary, the database is newer", id))
+	}
+	return diffs, nil
+}
+
+// tablesDiff compares the columns and indexes of the tables in the schema with
+// the expected ones.
+func tablesDiff(db *sql.DB, schema string, expected map[string]schemaTable) ([]string, error) {
+	columns := make(map[string]map[string]schemaColumn)
+	rows, err := db.Query(`SELECT table_name, column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = $1`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the columns of schema %s: %w", schema, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column, dataType, nullable string
+		if err := rows.Scan(&table, &column, &dataType, &nullable); err != nil {
+			return nil, err
+		}
+		if columns[table] == nil {
+			columns[table] = make(map[string]schemaColumn)
+		}
+		columns[table][column] = schemaColumn{dataType: dataType, nullable: nullable == "YES"}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]map[string]bool)
+	indexRows, err := db.Query(`SELECT tablename, indexname FROM pg_indexes WHERE schemaname = $1`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the indexes of schema %s: %w", schema, err)
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var table, index string
+		if err := indexRows.Scan(&table, &index); err != nil {
+			return nil, err
+		}
+		if indexes[table] == nil {
+			indexes[table] = make(map[string]bool)
+		}
+		indexes[table][index] = true
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	for _, tableName := range sortedKeys(expected) {
+		table := expected[tableName]
+		actualColumns, ok := columns[tableName]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("table %s.%s is missing", schema, tableName))
+			continue
+		}
+		for _, columnName := range sortedKeys(table.columns) {
+			want := table.columns[columnName]
+			got, ok := actualColumns[columnName]
+			switch {
+			case !ok:
+				diffs = append(diffs, fmt.Sprintf("column %s.%s.%s is missing", schema, tableName, columnName))
+			case got.dataType != want.dataType:
+				diffs = append(diffs, fmt.Sprintf("column %s.%s.%s has type %s, expected %s", schema, tableName, columnName, got.dataType, want.dataType))
+			case got.nullable != want.nullable:
+				diffs = append(diffs, fmt.Sprintf("column %s.%s.%s nullable is %t, expected %t", schema, tableName, columnName, got.nullable, want.nullable))
+			}
+		}
+		for _, index := range table.indexes {
+			if !indexes[tableName][index] {
+				diffs = append(diffs, fmt.Sprintf("index %s.%s on table %s is missing", schema, index, tableName))
+			}
+		}
+	}
+	return diffs, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
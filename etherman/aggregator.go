@@ -17,8 +17,21 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// trustedAggregatorRole is the RollupManager role required to call
+// VerifyBatchesTrustedAggregator
+var trustedAggregatorRole = crypto.Keccak256Hash([]byte("TRUSTED_AGGREGATOR_ROLE"))
+
 // GetLatestVerifiedBatchNum gets latest verified batch from ethereum
 func (etherMan *Client) GetLatestVerifiedBatchNum() (uint64, error) {
+	if etherMan.subscriptions != nil {
+		if lastVerifiedBatchNum, live := etherMan.subscriptions.latestVerifiedBatch(); live {
+			return lastVerifiedBatchNum, nil
+		}
+	}
+	return etherMan.getLatestVerifiedBatchNumFromL1()
+}
+
+func (etherMan *Client) getLatestVerifiedBatchNumFromL1() (uint64, error) {
 	var lastVerifiedBatchNum uint64
 	rollupData, err := etherMan.RollupManager.RollupIDToRollupData(&bind.CallOpts{Pending: false}, etherMan.RollupID)
 	if err != nil {
@@ -82,6 +95,11 @@ func (etherMan *Client) BuildTrustedVerifyBatchesTxData(lastVerifiedBatch, newVe
 
 // GetLatestBlockHeader gets the latest block header from the ethereum
 func (etherMan *Client) GetLatestBlockHeader(ctx context.Context) (*types.Header, error) {
+	if etherMan.subscriptions != nil {
+		if header, live := etherMan.subscriptions.latestHeader(); live {
+			return header, nil
+		}
+	}
 	header, err := etherMan.EthClient.HeaderByNumber(ctx, big.NewInt(int64(rpc.LatestBlockNumber)))
 	if err != nil || header == nil {
 		return nil, err
@@ -98,6 +116,43 @@ func (etherman *Client) GetBatchAccInputHash(ctx context.Context, batchNumber ui
 	return rollupData.AccInputHash, nil
 }
 
+// GetBatchStateRoot gets the state root of a verified batch from the ethereum
+func (etherMan *Client) GetBatchStateRoot(ctx context.Context, batchNumber uint64) (common.Hash, error) {
+	return etherMan.RollupManager.GetRollupBatchNumToStateRoot(&bind.CallOpts{Pending: false, Context: ctx}, etherMan.RollupID, batchNumber)
+}
+
+// HasTrustedAggregatorRole returns true if the account is allowed to verify
+// batches as trusted aggregator on the RollupManager
+func (etherMan *Client) HasTrustedAggregatorRole(ctx context.Context, account common.Address) (bool, error) {
+	return etherMan.RollupManager.HasRole(&bind.CallOpts{Pending: false, Context: ctx}, trustedAggregatorRole, account)
+}
+
+// GetRollupVerifier returns the verifier currently used by the RollupManager
+// to check the proofs of the rollup
+func (etherMan *Client) GetRollupVerifier(ctx context.Context) (*ethmanTypes.RollupVerifier, error) {
+	rollupData, err := etherMan.RollupManager.RollupIDToRollupData(&bind.CallOpts{Pending: false, Context: ctx}, etherMan.RollupID)
+	if err != nil {
+		return nil, err
+	}
+	return &ethmanTypes.RollupVerifier{
+		Verifier:     rollupData.Verifier,
+		ForkID:       rollupData.ForkID,
+		RollupTypeID: rollupData.RollupTypeID,
+	}, nil
+}
+
+// CurrentNonce returns the nonce of the account at the latest block, i.e. the
+// number of txs of the account that have been mined
+func (etherMan *Client) CurrentNonce(ctx context.Context, account common.Address) (uint64, error) {
+	return etherMan.EthClient.NonceAt(ctx, account, nil)
+}
+
+// PendingNonce returns the next nonce of the account, including the txs
+// waiting in the node mempool
+func (etherMan *Client) PendingNonce(ctx context.Context, account common.Address) (uint64, error) {
+	return etherMan.EthClient.PendingNonceAt(ctx, account)
+}
+
 // GetRollupId returns the rollup id
 func (etherMan *Client) GetRollupId() uint32 {
 	return etherMan.RollupID
@@ -139,6 +194,15 @@ func convertProof(p string) ([24][32]byte, error) {
 	return proof, nil
 }
 
+// GetL1ChainID returns the chain ID of the L1 node the client is connected to
+func (etherMan *Client) GetL1ChainID(ctx context.Context) (uint64, error) {
+	chainID, err := etherMan.EthClient.ChainID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return chainID.Uint64(), nil
+}
+
 // GetL2ChainID returns L2 Chain ID
 func (etherMan *Client) GetL2ChainID() (uint64, error) {
 	chainID, err := etherMan.OldZkEVM.ChainID(&bind.CallOpts{Pending: false})
@@ -0,0 +1,93 @@
+package etherman_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/aggregator/prover"
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/ethermantest"
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFinalProofInputs() *ethmanTypes.FinalProofInputs {
+	return &ethmanTypes.FinalProofInputs{
+		FinalProof:       &prover.FinalProof{Proof: "0x" + strings.Repeat("01", 24*32)},
+		NewLocalExitRoot: common.HexToHash("0x01").Bytes(),
+		NewStateRoot:     common.HexToHash("0x02").Bytes(),
+	}
+}
+
+func TestBuildTrustedVerifyBatchesTxData(t *testing.T) {
+	l1 := ethermantest.NewSimulatedL1(t)
+	beneficiary := common.HexToAddress("0xbeef")
+
+	to, data, err := l1.Client.BuildTrustedVerifyBatchesTxData(0, 5, newFinalProofInputs(), beneficiary)
+	require.NoError(t, err)
+	require.NotNil(t, to)
+	assert.Equal(t, l1.RollupManagerAddr, *to)
+
+	rollupManagerABI, err := polygonrollupmanager.PolygonrollupmanagerMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := rollupManagerABI.MethodById(data[:4])
+	require.NoError(t, err)
+	assert.Equal(t, "verifyBatchesTrustedAggregator", method.Name)
+	args, err := method.Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+	assert.Equal(t, l1.Client.RollupID, args[0])
+	assert.Equal(t, uint64(0), args[2])
+	assert.Equal(t, uint64(5), args[3])
+	assert.Equal(t, beneficiary, args[6])
+
+	// the funded account is the trusted aggregator: the call passes the role
+	// check and reverts because no batch has been sequenced
+	simulation, err := l1.Client.SimulateCall(context.Background(), l1.Auth.From, to, data, false)
+	require.NoError(t, err)
+	assert.True(t, simulation.Reverted)
+	assert.False(t, strings.HasPrefix(simulation.RevertReason, "AddressDoNotHaveRequiredRole"), simulation.RevertReason)
+
+	simulation, err = l1.Client.SimulateCall(context.Background(), common.HexToAddress("0xdead"), to, data, false)
+	require.NoError(t, err)
+	assert.True(t, simulation.Reverted)
+	assert.True(t, strings.HasPrefix(simulation.RevertReason, "AddressDoNotHaveRequiredRole"), simulation.RevertReason)
+}
+
+func TestRollupData(t *testing.T) {
+	l1 := ethermantest.NewSimulatedL1(t)
+	ctx := context.Background()
+
+	assert.Equal(t, uint32(1), l1.Client.GetRollupId())
+
+	verifier, err := l1.Client.GetRollupVerifier(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, l1.VerifierAddr, verifier.Verifier)
+	assert.Equal(t, uint64(ethermantest.ForkID), verifier.ForkID)
+
+	l2ChainID, err := l1.Client.GetL2ChainID()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(ethermantest.L2ChainID), l2ChainID)
+
+	hasRole, err := l1.Client.HasTrustedAggregatorRole(ctx, l1.Auth.From)
+	require.NoError(t, err)
+	assert.True(t, hasRole)
+	hasRole, err = l1.Client.HasTrustedAggregatorRole(ctx, common.HexToAddress("0xdead"))
+	require.NoError(t, err)
+	assert.False(t, hasRole)
+
+	lastVerifiedBatch, err := l1.Client.GetLatestVerifiedBatchNum()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), lastVerifiedBatch)
+}
+
+func TestBuildTrustedVerifyBatchesTxDataInvalidProof(t *testing.T) {
+	l1 := ethermantest.NewSimulatedL1(t)
+
+	inputs := newFinalProofInputs()
+	inputs.FinalProof.Proof = "0x01"
+	_, _, err := l1.Client.BuildTrustedVerifyBatchesTxData(1, 5, inputs, common.Address{})
+	require.ErrorContains(t, err, "invalid proof length")
+}
@@ -1,7 +1,20 @@
 package etherman
 
+import "github.com/0xPolygonHermez/zkevm-aggregator/proxy"
+
 // Config represents the configuration of the etherman
 type Config struct {
 	// URL is the URL of the Ethereum node for L1
 	URL string `mapstructure:"URL"`
+	// WSURL is the websocket URL of the Ethereum node for L1. If set, the
+	// L1 heads and the batch verifications are received through subscriptions
+	// instead of polling URL
+	WSURL string `mapstructure:"WSURL"`
+	// FilterLogsChunkSize is the maximum number of blocks covered by a single
+	// logs query, it is reduced automatically if the provider rejects the range
+	FilterLogsChunkSize uint64 `mapstructure:"FilterLogsChunkSize"`
+
+	// Proxy the L1 connections go through, set from the top level Proxy
+	// configuration
+	Proxy proxy.Config `mapstructure:"-"`
 }
@@ -0,0 +1,21 @@
+package etherman
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryParseError(t *testing.T) {
+	parsedErr, ok := tryParseError(errors.New("gas required exceeds allowance"))
+	assert.True(t, ok)
+	assert.ErrorIs(t, parsedErr, ErrGasRequiredExceedsAllowance)
+
+	parsedErr, ok = tryParseError(errors.New("failed to estimate gas: insufficient allowance (supplied gas 21000)"))
+	assert.True(t, ok)
+	assert.ErrorIs(t, parsedErr, ErrInsufficientAllowance)
+
+	_, ok = tryParseError(errors.New("execution reverted"))
+	assert.False(t, ok)
+}
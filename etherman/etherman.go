@@ -1,6 +1,9 @@
 package etherman
 
 import (
+	"context"
+	"math/big"
+
 	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/metrics"
 	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/oldpolygonzkevm"
 	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
@@ -13,6 +16,19 @@ import (
 
 type ethereumClient interface {
 	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.PendingStateReader
+	ethereum.ContractCaller
+	ethereum.GasEstimator
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// EthereumBackend is the connection to L1 used to create the etherman, it is
+// satisfied both by ethclient.Client and by the client of the go-ethereum
+// simulated backend
+type EthereumBackend interface {
+	ethereumClient
+	bind.ContractBackend
 }
 
 // L1Config represents the configuration of the network used in L1
@@ -32,23 +48,34 @@ type Client struct {
 	EthClient     ethereumClient
 	OldZkEVM      *oldpolygonzkevm.Oldpolygonzkevm
 	RollupManager *polygonrollupmanager.Polygonrollupmanager
-	SCAddresses   []common.Address
+	// GlobalExitRootManager only binds the methods read by the aggregator
+	GlobalExitRootManager *bind.BoundContract
+	SCAddresses           []common.Address
 
 	RollupID uint32
 
 	l1Cfg L1Config
 	cfg   Config
 	auth  map[common.Address]bind.TransactOpts // empty in case of read-only client
+	// latest values received through the websocket subscriptions, nil if
+	// they have not been started
+	subscriptions *l1Subscriptions
 }
 
 // NewClient creates a new etherman.
 func NewClient(cfg Config, l1Config L1Config) (*Client, error) {
 	// Connect to ethereum node
-	ethClient, err := ethclient.Dial(cfg.URL)
+	rpcClient, err := cfg.Proxy.DialRPC(context.Background(), cfg.URL)
 	if err != nil {
 		log.Errorf("error connecting to %s: %+v", cfg.URL, err)
 		return nil, err
 	}
+	return NewClientWithBackend(ethclient.NewClient(rpcClient), cfg, l1Config)
+}
+
+// NewClientWithBackend creates a new etherman using an already connected L1
+// backend, e.g. a simulated one in tests.
+func NewClientWithBackend(ethClient EthereumBackend, cfg Config, l1Config L1Config) (*Client, error) {
 	// Create smc clients
 	oldZkevm, err := oldpolygonzkevm.NewOldpolygonzkevm(l1Config.RollupManagerAddr, ethClient)
 	if err != nil {
@@ -60,6 +87,11 @@ func NewClient(cfg Config, l1Config L1Config) (*Client, error) {
 		log.Errorf("error creating NewPolygonrollupmanager client (%s). Error: %w", l1Config.RollupManagerAddr.String(), err)
 		return nil, err
 	}
+	globalExitRootManager, err := newGlobalExitRootManager(l1Config.GlobalExitRootManagerAddr, ethClient)
+	if err != nil {
+		log.Errorf("error creating GlobalExitRootManager client (%s). Error: %w", l1Config.GlobalExitRootManagerAddr.String(), err)
+		return nil, err
+	}
 
 	var scAddresses []common.Address
 	scAddresses = append(scAddresses, l1Config.ZkEVMAddr, l1Config.RollupManagerAddr)
@@ -74,13 +106,14 @@ func NewClient(cfg Config, l1Config L1Config) (*Client, error) {
 	log.Debug("rollupID: ", rollupID)
 
 	return &Client{
-		EthClient:     ethClient,
-		OldZkEVM:      oldZkevm,
-		RollupManager: rollupManager,
-		SCAddresses:   scAddresses,
-		RollupID:      rollupID,
-		l1Cfg:         l1Config,
-		cfg:           cfg,
-		auth:          map[common.Address]bind.TransactOpts{},
+		EthClient:             ethClient,
+		OldZkEVM:              oldZkevm,
+		RollupManager:         rollupManager,
+		GlobalExitRootManager: globalExitRootManager,
+		SCAddresses:           scAddresses,
+		RollupID:              rollupID,
+		l1Cfg:                 l1Config,
+		cfg:                   cfg,
+		auth:                  map[common.Address]bind.TransactOpts{},
 	}, nil
 }
@@ -0,0 +1,152 @@
+// Package ethermantest provides a simulated L1 to test the etherman, and the
+// components built on top of it, against the real contract bindings without
+// connecting to a node.
+package ethermantest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman"
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/proxy"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// ForkID is the fork id of the rollup added to the simulated L1
+	ForkID = 9
+	// L2ChainID is the chain id of the rollup added to the simulated L1
+	L2ChainID = 1001
+	// pendingStateTimeout and trustedAggregatorTimeout are the values the
+	// RollupManager is initialized with, in seconds
+	pendingStateTimeout      = 3600
+	trustedAggregatorTimeout = 3600
+)
+
+var (
+	// ProxyAdminAddr is the admin of the RollupManager proxy. It must not be
+	// the deployer: the transparent proxy does not forward the calls of its
+	// admin to the implementation.
+	ProxyAdminAddr = common.HexToAddress("0x0000000000000000000000000000000000000a10")
+
+	// fundedBalance is the balance of the deployer account, enough for any test
+	fundedBalance, _ = new(big.Int).SetString("1000000000000000000000000", 10) //nolint:gomnd
+
+	// stubCode is the init code of a contract that returns the word 1 to any
+	// call, which is enough to act as a verifier accepting every proof, as a
+	// POL token accepting every transfer, and as a consensus contract
+	// accepting the calls of the RollupManager
+	stubCode = common.FromHex("0x600a600c600039600a6000f3" + "600160005260206000f3")
+)
+
+// SimulatedL1 is an in-memory L1 with an initialized RollupManager, a rollup
+// added to it and an etherman connected to it.
+//
+// The verifier, POL, bridge, global exit root manager and zkEVM contracts are
+// stubs accepting any call. The funded account holds every RollupManager role,
+// including the trusted aggregator one, so it can verify batches and add or
+// update rollups; any other account gets AddressDoNotHaveRequiredRole. No
+// batch is sequenced, as only the zkEVM contract can sequence them.
+type SimulatedL1 struct {
+	// Backend is the simulated chain, new blocks are mined with Commit
+	Backend *simulated.Backend
+	// Key is the private key of the funded account that deployed the contracts
+	Key *ecdsa.PrivateKey
+	// Auth signs the txs of the funded account
+	Auth *bind.TransactOpts
+	// RollupManagerAddr is the address of the RollupManager proxy
+	RollupManagerAddr common.Address
+	// RollupManager is the binding of the RollupManager proxy
+	RollupManager *polygonrollupmanager.Polygonrollupmanager
+	// ZkEVMAddr is the address of the consensus contract of the rollup
+	ZkEVMAddr common.Address
+	// VerifierAddr is the address of the verifier of the rollup
+	VerifierAddr common.Address
+	// GlobalExitRootManagerAddr is the address of the global exit root manager
+	GlobalExitRootManagerAddr common.Address
+	// Client is the etherman connected to the simulated L1
+	Client *etherman.Client
+}
+
+// NewSimulatedL1 starts a simulated L1, deploys and initializes the
+// RollupManager with a rollup and creates an etherman connected to it. The
+// backend is closed when the test finishes.
+func NewSimulatedL1(t testing.TB) *SimulatedL1 {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		deployer: {Balance: fundedBalance},
+	})
+	t.Cleanup(func() { _ = backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	require.NoError(t, err)
+
+	l1 := &SimulatedL1{
+		Backend: backend,
+		Key:     key,
+		Auth:    auth,
+	}
+	l1.ZkEVMAddr = l1.DeployStub(t)
+	l1.VerifierAddr = l1.DeployStub(t)
+	l1.GlobalExitRootManagerAddr = l1.DeployStub(t)
+	polAddr := l1.DeployStub(t)
+	bridgeAddr := l1.DeployStub(t)
+
+	implementationAddr, _, _, err := polygonrollupmanager.DeployPolygonrollupmanager(auth, backend.Client(), l1.GlobalExitRootManagerAddr, polAddr, bridgeAddr)
+	require.NoError(t, err)
+	backend.Commit()
+
+	// the implementation disables its initializers, so the RollupManager is
+	// initialized through the proxy constructor, as done in the real deployment
+	rollupManagerABI, err := polygonrollupmanager.PolygonrollupmanagerMetaData.GetAbi()
+	require.NoError(t, err)
+	initializeData, err := rollupManagerABI.Pack("initialize",
+		deployer, uint64(pendingStateTimeout), uint64(trustedAggregatorTimeout),
+		deployer, deployer, deployer,
+		l1.ZkEVMAddr, l1.VerifierAddr, uint64(ForkID), uint64(L2ChainID),
+	)
+	require.NoError(t, err)
+	l1.RollupManagerAddr, _, _, err = proxy.DeployProxy(auth, backend.Client(), implementationAddr, ProxyAdminAddr, initializeData)
+	require.NoError(t, err)
+	backend.Commit()
+
+	l1.RollupManager, err = polygonrollupmanager.NewPolygonrollupmanager(l1.RollupManagerAddr, backend.Client())
+	require.NoError(t, err)
+
+	l1.Client, err = etherman.NewClientWithBackend(backend.Client(), etherman.Config{}, etherman.L1Config{
+		L1ChainID:                 chainID.Uint64(),
+		ZkEVMAddr:                 l1.ZkEVMAddr,
+		RollupManagerAddr:         l1.RollupManagerAddr,
+		GlobalExitRootManagerAddr: l1.GlobalExitRootManagerAddr,
+	})
+	require.NoError(t, err)
+
+	return l1
+}
+
+// DeployStub deploys a contract that returns the word 1 to any call and mines
+// it, e.g. to add other rollups or verifiers to the RollupManager.
+func (l1 *SimulatedL1) DeployStub(t testing.TB) common.Address {
+	t.Helper()
+
+	addr, _, _, err := bind.DeployContract(l1.Auth, abi.ABI{}, stubCode, l1.Backend.Client())
+	require.NoError(t, err)
+	l1.Backend.Commit()
+	return addr
+}
@@ -0,0 +1,58 @@
+package etherman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// globalExitRootManagerABI is the subset of the GlobalExitRootManager ABI read
+// by the aggregator
+const globalExitRootManagerABI = `[{"inputs":[],"name":"lastRollupExitRoot","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}]`
+
+// newGlobalExitRootManager binds the GlobalExitRootManager contract
+func newGlobalExitRootManager(address common.Address, caller bind.ContractCaller) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(globalExitRootManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, nil, nil), nil
+}
+
+// GetExitRoots returns the exit roots settled on L1 for the rollup. All the
+// values are read at the same block.
+func (etherMan *Client) GetExitRoots(ctx context.Context) (*ethmanTypes.ExitRoots, error) {
+	header, err := etherMan.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+	rollupData, err := etherMan.RollupManager.RollupIDToRollupData(opts, etherMan.RollupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup data: %w", err)
+	}
+	rollupExitRoot, err := etherMan.RollupManager.GetRollupExitRoot(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup exit root: %w", err)
+	}
+
+	var out []interface{}
+	err = etherMan.GlobalExitRootManager.Call(opts, &out, "lastRollupExitRoot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the rollup exit root of the global exit root manager: %w", err)
+	}
+	globalRollupExitRoot := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return &ethmanTypes.ExitRoots{
+		LastVerifiedBatch:    rollupData.LastVerifiedBatch,
+		LastLocalExitRoot:    rollupData.LastLocalExitRoot,
+		RollupExitRoot:       rollupExitRoot,
+		GlobalRollupExitRoot: globalRollupExitRoot,
+	}, nil
+}
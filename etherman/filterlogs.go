@@ -0,0 +1,150 @@
+package etherman
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
+	ethmanTypes "github.com/0xPolygonHermez/zkevm-aggregator/etherman/types"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// defaultFilterLogsChunkSize is the number of blocks queried at once when the
+// chunk size is not configured
+const defaultFilterLogsChunkSize = 10000
+
+// rangeLimitErrors are fragments of the errors returned by the L1 providers
+// when a logs query covers too many blocks or returns too many results
+var rangeLimitErrors = []string{
+	"query returned more than",
+	"block range",
+	"range is too large",
+	"limit exceeded",
+	"too many",
+	"response size exceeded",
+}
+
+// isRangeLimitError returns true if the error is caused by the size of the
+// logs query, so it may succeed with a smaller block range
+func isRangeLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range rangeLimitErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLogsInChunks calls filter for consecutive block ranges covering
+// [fromBlock, toBlock]. The range is halved when the provider rejects it for
+// being too large, and grows back up to the configured chunk size after each
+// successful query.
+func (etherMan *Client) filterLogsInChunks(ctx context.Context, fromBlock, toBlock uint64, filter func(opts *bind.FilterOpts) error) error {
+	maxChunkSize := etherMan.cfg.FilterLogsChunkSize
+	if maxChunkSize == 0 {
+		maxChunkSize = defaultFilterLogsChunkSize
+	}
+	chunkSize := maxChunkSize
+
+	for start := fromBlock; start <= toBlock; {
+		end := start + chunkSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+
+		err := filter(&bind.FilterOpts{Start: start, End: &end, Context: ctx})
+		if err != nil {
+			if !isRangeLimitError(err) || chunkSize == 1 {
+				return fmt.Errorf("failed to filter logs in blocks %d-%d: %w", start, end, err)
+			}
+			chunkSize /= 2
+			log.Debugf("Logs query for blocks %d-%d too large, retrying with %d blocks: %v", start, end, chunkSize, err)
+			continue
+		}
+
+		start = end + 1
+		if start == 0 {
+			// toBlock is the max block number
+			break
+		}
+		if chunkSize < maxChunkSize {
+			chunkSize *= 2
+			if chunkSize > maxChunkSize {
+				chunkSize = maxChunkSize
+			}
+		}
+	}
+	return nil
+}
+
+// GetVerifyBatchesTrustedAggregatorEvents returns the verifications of batches
+// of the rollup made by trusted aggregators in the block range [fromBlock, toBlock]
+func (etherMan *Client) GetVerifyBatchesTrustedAggregatorEvents(ctx context.Context, fromBlock, toBlock uint64) ([]*polygonrollupmanager.PolygonrollupmanagerVerifyBatchesTrustedAggregator, error) {
+	var events []*polygonrollupmanager.PolygonrollupmanagerVerifyBatchesTrustedAggregator
+	err := etherMan.filterLogsInChunks(ctx, fromBlock, toBlock, func(opts *bind.FilterOpts) error {
+		iter, err := etherMan.RollupManager.FilterVerifyBatchesTrustedAggregator(opts, []uint32{etherMan.RollupID}, nil)
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+
+		var chunkEvents []*polygonrollupmanager.PolygonrollupmanagerVerifyBatchesTrustedAggregator
+		for iter.Next() {
+			chunkEvents = append(chunkEvents, iter.Event)
+		}
+		if err := iter.Error(); err != nil {
+			return err
+		}
+		events = append(events, chunkEvents...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetRollupSequences returns the sequences of batches of the rollup recorded by
+// the RollupManager in the block range [fromBlock, toBlock], read from its
+// OnSequenceBatches events
+func (etherMan *Client) GetRollupSequences(ctx context.Context, fromBlock, toBlock uint64) ([]ethmanTypes.RollupSequence, error) {
+	var sequences []ethmanTypes.RollupSequence
+	err := etherMan.filterLogsInChunks(ctx, fromBlock, toBlock, func(opts *bind.FilterOpts) error {
+		iter, err := etherMan.RollupManager.FilterOnSequenceBatches(opts, []uint32{etherMan.RollupID})
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+
+		var chunkSequences []ethmanTypes.RollupSequence
+		for iter.Next() {
+			event := iter.Event
+			callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(event.Raw.BlockNumber)}
+			data, err := etherMan.RollupManager.GetRollupSequencedBatches(callOpts, etherMan.RollupID, event.LastBatchSequenced)
+			if err != nil {
+				return fmt.Errorf("failed to get the sequence of batch %d: %w", event.LastBatchSequenced, err)
+			}
+			chunkSequences = append(chunkSequences, ethmanTypes.RollupSequence{
+				FromBatchNumber: data.PreviousLastBatchSequenced + 1,
+				ToBatchNumber:   event.LastBatchSequenced,
+				L1BlockNumber:   event.Raw.BlockNumber,
+				Timestamp:       time.Unix(int64(data.SequencedTimestamp), 0),
+				AccInputHash:    data.AccInputHash,
+			})
+		}
+		if err := iter.Error(); err != nil {
+			return err
+		}
+		sequences = append(sequences, chunkSequences...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequences, nil
+}
@@ -0,0 +1,102 @@
+package etherman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// CallSimulation is the result of running a tx against the current L1 state
+// without sending it
+type CallSimulation struct {
+	// Reverted is true if the call reverted
+	Reverted bool
+	// RevertReason is the decoded revert reason, e.g. the name of the
+	// RollupManager custom error, or the raw error if it can not be decoded
+	RevertReason string
+	// GasUsed is the estimated gas of the call, only set if it did not revert
+	GasUsed uint64
+	// Trace is the call trace returned by debug_traceCall, if requested
+	Trace json.RawMessage
+}
+
+// SimulateCall runs a call against the latest L1 state of the node, e.g. a
+// fork of L1 run by Anvil or Hardhat, and decodes the revert reason using the
+// RollupManager errors. If trace is true, the call is also traced with the
+// callTracer, which requires the debug namespace to be enabled in the node.
+func (etherMan *Client) SimulateCall(ctx context.Context, from common.Address, to *common.Address, data []byte, trace bool) (*CallSimulation, error) {
+	msg := ethereum.CallMsg{From: from, To: to, Data: data}
+	simulation := &CallSimulation{}
+
+	if _, err := etherMan.EthClient.CallContract(ctx, msg, nil); err != nil {
+		simulation.Reverted = true
+		simulation.RevertReason = decodeRollupManagerError(err)
+	} else {
+		gas, err := etherMan.EthClient.EstimateGas(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		simulation.GasUsed = gas
+	}
+
+	if trace {
+		client, err := etherMan.cfg.Proxy.DialRPC(ctx, etherMan.cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", etherMan.cfg.URL, err)
+		}
+		defer client.Close()
+
+		args := map[string]interface{}{
+			"from": from,
+			"to":   to,
+			"data": hexutil.Bytes(data),
+		}
+		tracer := map[string]interface{}{"tracer": "callTracer"}
+		if err := client.CallContext(ctx, &simulation.Trace, "debug_traceCall", args, "latest", tracer); err != nil {
+			return nil, fmt.Errorf("failed to trace call: %w", err)
+		}
+	}
+
+	return simulation, nil
+}
+
+// decodeRollupManagerError returns the name and arguments of the RollupManager
+// custom error a call reverted with, or the error itself if it can not be
+// decoded.
+func decodeRollupManagerError(err error) string {
+	dataErr, ok := err.(rpc.DataError)
+	if !ok {
+		return err.Error()
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	revertData, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil || len(revertData) < 4 { //nolint:gomnd
+		return err.Error()
+	}
+
+	rollupManagerABI, parseErr := polygonrollupmanager.PolygonrollupmanagerMetaData.GetAbi()
+	if parseErr != nil {
+		return err.Error()
+	}
+	for name, abiError := range rollupManagerABI.Errors {
+		if !bytes.Equal(abiError.ID[:4], revertData[:4]) {
+			continue
+		}
+		args, unpackErr := abiError.Unpack(revertData)
+		if unpackErr != nil || args == nil {
+			return name
+		}
+		return fmt.Sprintf("%s%v", name, args)
+	}
+	return err.Error()
+}
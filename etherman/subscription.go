@@ -0,0 +1,177 @@
+package etherman
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/etherman/smartcontracts/polygonrollupmanager"
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defaultResubscribeInterval is the time waited before subscribing again when
+// the websocket subscriptions fail
+const defaultResubscribeInterval = 5 * time.Second
+
+// l1Subscriptions holds the latest L1 head and verified batch received through
+// the websocket subscriptions. The values are only used while the
+// subscriptions are live, the client falls back to HTTP polling otherwise.
+type l1Subscriptions struct {
+	mutex             sync.RWMutex
+	live              bool
+	head              *types.Header
+	lastVerifiedBatch uint64
+}
+
+func (s *l1Subscriptions) latestHeader() (*types.Header, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if !s.live || s.head == nil {
+		return nil, false
+	}
+	return types.CopyHeader(s.head), true
+}
+
+func (s *l1Subscriptions) latestVerifiedBatch() (uint64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastVerifiedBatch, s.live
+}
+
+// StartSubscriptions subscribes to the L1 heads and the verifications of the
+// rollup batches through the websocket endpoint, resubscribing when the
+// connection drops, until the context is done. The values missed while the
+// subscriptions were down are read through HTTP on every resubscription.
+func (etherMan *Client) StartSubscriptions(ctx context.Context) error {
+	if etherMan.cfg.WSURL == "" {
+		return fmt.Errorf("no websocket URL configured")
+	}
+	etherMan.subscriptions = &l1Subscriptions{}
+
+	go func() {
+		for {
+			err := etherMan.subscribe(ctx)
+			etherMan.subscriptions.mutex.Lock()
+			etherMan.subscriptions.live = false
+			etherMan.subscriptions.mutex.Unlock()
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warnf("L1 websocket subscriptions dropped, polling L1 through HTTP until resubscribed: %v", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(defaultResubscribeInterval):
+			}
+		}
+	}()
+	return nil
+}
+
+// subscribe runs the subscriptions until one of them fails
+func (etherMan *Client) subscribe(ctx context.Context) error {
+	rpcClient, err := etherMan.cfg.Proxy.DialRPC(ctx, etherMan.cfg.WSURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", etherMan.cfg.WSURL, err)
+	}
+	wsClient := ethclient.NewClient(rpcClient)
+	defer wsClient.Close()
+
+	rollupManager, err := polygonrollupmanager.NewPolygonrollupmanagerFilterer(etherMan.l1Cfg.RollupManagerAddr, wsClient)
+	if err != nil {
+		return err
+	}
+
+	heads := make(chan *types.Header)
+	headSub, err := wsClient.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the L1 heads: %w", err)
+	}
+	defer headSub.Unsubscribe()
+
+	rollupIDs := []uint32{etherMan.RollupID}
+	verifications := make(chan *polygonrollupmanager.PolygonrollupmanagerVerifyBatches)
+	verifySub, err := rollupManager.WatchVerifyBatches(&bind.WatchOpts{Context: ctx}, verifications, rollupIDs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the batch verifications: %w", err)
+	}
+	defer verifySub.Unsubscribe()
+
+	trustedVerifications := make(chan *polygonrollupmanager.PolygonrollupmanagerVerifyBatchesTrustedAggregator)
+	trustedVerifySub, err := rollupManager.WatchVerifyBatchesTrustedAggregator(&bind.WatchOpts{Context: ctx}, trustedVerifications, rollupIDs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the trusted batch verifications: %w", err)
+	}
+	defer trustedVerifySub.Unsubscribe()
+
+	// fill the gap left while not subscribed
+	if err := etherMan.refreshSubscriptions(ctx); err != nil {
+		return err
+	}
+	log.Infof("Subscribed to the L1 heads and batch verifications through %s", etherMan.cfg.WSURL)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-headSub.Err():
+			return fmt.Errorf("L1 heads subscription: %w", err)
+		case err := <-verifySub.Err():
+			return fmt.Errorf("batch verifications subscription: %w", err)
+		case err := <-trustedVerifySub.Err():
+			return fmt.Errorf("trusted batch verifications subscription: %w", err)
+		case head := <-heads:
+			etherMan.subscriptions.mutex.Lock()
+			etherMan.subscriptions.head = head
+			etherMan.subscriptions.mutex.Unlock()
+		case event := <-verifications:
+			if err := etherMan.onVerification(ctx, event.NumBatch, event.Raw.Removed); err != nil {
+				return err
+			}
+		case event := <-trustedVerifications:
+			if err := etherMan.onVerification(ctx, event.NumBatch, event.Raw.Removed); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// onVerification updates the last verified batch with a verification event.
+// A removed event means L1 reorged, the last verified batch is read again.
+func (etherMan *Client) onVerification(ctx context.Context, numBatch uint64, removed bool) error {
+	if removed {
+		log.Warnf("Verification of batch %d removed from L1 by a reorg", numBatch)
+		return etherMan.refreshSubscriptions(ctx)
+	}
+	etherMan.subscriptions.mutex.Lock()
+	defer etherMan.subscriptions.mutex.Unlock()
+	if numBatch > etherMan.subscriptions.lastVerifiedBatch {
+		etherMan.subscriptions.lastVerifiedBatch = numBatch
+	}
+	return nil
+}
+
+// refreshSubscriptions reads the latest head and verified batch through HTTP
+// and marks the subscriptions live
+func (etherMan *Client) refreshSubscriptions(ctx context.Context) error {
+	head, err := etherMan.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read the latest L1 head: %w", err)
+	}
+	lastVerifiedBatch, err := etherMan.getLatestVerifiedBatchNumFromL1()
+	if err != nil {
+		return fmt.Errorf("failed to read the last verified batch: %w", err)
+	}
+
+	etherMan.subscriptions.mutex.Lock()
+	defer etherMan.subscriptions.mutex.Unlock()
+	etherMan.subscriptions.head = head
+	etherMan.subscriptions.lastVerifiedBatch = lastVerifiedBatch
+	etherMan.subscriptions.live = true
+	return nil
+}
@@ -0,0 +1,17 @@
+package types
+
+import "github.com/ethereum/go-ethereum/common"
+
+// ExitRoots are the exit roots settled on L1 for the rollup
+type ExitRoots struct {
+	// LastVerifiedBatch is the last batch of the rollup verified on L1
+	LastVerifiedBatch uint64
+	// LastLocalExitRoot is the local exit root of the rollup at LastVerifiedBatch
+	LastLocalExitRoot common.Hash
+	// RollupExitRoot is the root of the local exit roots of all the rollups,
+	// as computed by the RollupManager
+	RollupExitRoot common.Hash
+	// GlobalRollupExitRoot is the rollup exit root stored by the
+	// GlobalExitRootManager
+	GlobalRollupExitRoot common.Hash
+}
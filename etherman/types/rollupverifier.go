@@ -0,0 +1,11 @@
+package types
+
+import "github.com/ethereum/go-ethereum/common"
+
+// RollupVerifier identifies the verifier the RollupManager uses to check the
+// proofs of a rollup
+type RollupVerifier struct {
+	Verifier     common.Address
+	ForkID       uint64
+	RollupTypeID uint64
+}
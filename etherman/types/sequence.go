@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RollupSequence is a sequence of batches of the rollup as recorded by the
+// RollupManager
+type RollupSequence struct {
+	// FromBatchNumber is the first batch of the sequence
+	FromBatchNumber uint64
+	// ToBatchNumber is the last batch of the sequence
+	ToBatchNumber uint64
+	// L1BlockNumber is the L1 block where the batches were sequenced
+	L1BlockNumber uint64
+	// Timestamp is the time the batches were sequenced
+	Timestamp time.Time
+	// AccInputHash is the acc input hash of the last batch of the sequence
+	AccInputHash common.Hash
+}
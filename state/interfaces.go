@@ -3,19 +3,23 @@ package state
 import (
 	"context"
 
-	"github.com/jackc/pgconn"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v4"
 )
 
-type storage interface {
-	Exec(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
-	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
-	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+// Storage is the persistence of the state. The dbTx of the methods is a
+// transaction begun by the same storage, or nil to run outside of one.
+// Implementations are pgstatestorage, backed by Postgres, and
+// memstatestorage, backed by memory for tests and development setups.
+type Storage interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
-	AddSequence(ctx context.Context, sequence Sequence, dbTx pgx.Tx) error
+
+	// Proofs
 	CheckProofContainsCompleteSequences(ctx context.Context, proof *Proof, dbTx pgx.Tx) (bool, error)
 	GetProofReadyToVerify(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*Proof, error)
-	GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx) (*Proof, *Proof, error)
+	GetProofsToAggregate(ctx context.Context, maxBatches uint64, dbTx pgx.Tx) (*Proof, *Proof, error)
+	GetOldestProofToAggregate(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*Proof, error)
+	GetProofByID(ctx context.Context, proofID string, dbTx pgx.Tx) (*Proof, error)
 	AddGeneratedProof(ctx context.Context, proof *Proof, dbTx pgx.Tx) error
 	UpdateGeneratedProof(ctx context.Context, proof *Proof, dbTx pgx.Tx) error
 	DeleteGeneratedProofs(ctx context.Context, batchNumber uint64, batchNumberFinal uint64, dbTx pgx.Tx) error
@@ -23,8 +27,42 @@ type storage interface {
 	CleanupGeneratedProofs(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
 	CleanupLockedProofs(ctx context.Context, duration string, dbTx pgx.Tx) (int64, error)
 	CheckProofExistsForBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (bool, error)
+	GetProofPartitions(ctx context.Context, dbTx pgx.Tx) ([]ProofPartition, error)
+	AddProofPartition(ctx context.Context, partition ProofPartition, dbTx pgx.Tx) error
+	DropProofPartition(ctx context.Context, partition ProofPartition, dbTx pgx.Tx) error
+	GetProofs(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]Proof, error)
+
+	// Batches and sequences
+	AddSequence(ctx context.Context, sequence Sequence, dbTx pgx.Tx) error
 	AddBatch(ctx context.Context, batch *Batch, datastream []byte, dbTx pgx.Tx) error
 	GetBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*Batch, []byte, error)
+	GetBatches(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]Batch, error)
+	GetSequences(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]Sequence, error)
+	GetUnprovenBatchNumbers(ctx context.Context, lastVerifiedBatchNumber uint64, dbTx pgx.Tx) ([]uint64, error)
 	DeleteBatchesOlderThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
 	DeleteBatchesNewerThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error
+
+	// Records
+	AddAuditLogEntry(ctx context.Context, entry *AuditLogEntry, dbTx pgx.Tx) error
+	GetAuditLog(ctx context.Context, action string, limit uint64, dbTx pgx.Tx) ([]AuditLogEntry, error)
+	AddBatchStats(ctx context.Context, stats *BatchStats, dbTx pgx.Tx) error
+	GetBatchStats(ctx context.Context, fromBatchNumber, toBatchNumber uint64, dbTx pgx.Tx) ([]BatchStats, error)
+	AddL1Intent(ctx context.Context, intent *L1Intent, dbTx pgx.Tx) error
+	UpdateL1Intent(ctx context.Context, intent *L1Intent, dbTx pgx.Tx) error
+	UpdateL1IntentStatusByMonitoredTxID(ctx context.Context, monitoredTxID common.Hash, status L1IntentStatus, dbTx pgx.Tx) error
+	GetUnresolvedL1Intents(ctx context.Context, dbTx pgx.Tx) ([]L1Intent, error)
+	GetL1Intents(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]L1Intent, error)
+	AddFailedProof(ctx context.Context, failedProof *FailedProof, dbTx pgx.Tx) error
+	SetFailedProofExported(ctx context.Context, id uint64, dbTx pgx.Tx) error
+	GetFailedProofs(ctx context.Context, limit uint64, dbTx pgx.Tx) ([]FailedProof, error)
+	AddFinalProofPublicInputs(ctx context.Context, publicInputs *FinalProofPublicInputs, dbTx pgx.Tx) error
+	GetFinalProofPublicInputs(ctx context.Context, batchNumber uint64, limit uint64, dbTx pgx.Tx) ([]FinalProofPublicInputs, error)
+
+	// Batch range locks, held until dbTx ends
+	LockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) error
+	TryLockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) (bool, error)
+
+	// Snapshots
+	ExportSnapshot(ctx context.Context, dbTx pgx.Tx) (*Snapshot, error)
+	ImportSnapshot(ctx context.Context, snapshot *Snapshot, dbTx pgx.Tx) error
 }
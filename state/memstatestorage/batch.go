@@ -0,0 +1,137 @@
+package memstatestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddBatch stores a batch
+func (s *MemoryStorage) AddBatch(ctx context.Context, batch *state.Batch, datastream []byte, dbTx pgx.Tx) error {
+	stored := memBatch{batch: *batch, datastream: append([]byte(nil), datastream...)}
+	stored.batch.BatchL2Data = append([]byte(nil), batch.BatchL2Data...)
+	return s.write(dbTx, func(d *memData) error {
+		d.batches[stored.batch.BatchNumber] = stored
+		return nil
+	})
+}
+
+// GetBatch gets a batch by a given batch number
+func (s *MemoryStorage) GetBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (*state.Batch, []byte, error) {
+	var (
+		batch      state.Batch
+		datastream []byte
+	)
+	err := s.read(dbTx, func(d *memData) error {
+		stored, ok := d.batches[batchNumber]
+		if !ok {
+			return state.ErrNotFound
+		}
+		batch = stored.batch
+		batch.BatchL2Data = append([]byte(nil), stored.batch.BatchL2Data...)
+		datastream = append([]byte(nil), stored.datastream...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &batch, datastream, nil
+}
+
+// DeleteBatchesOlderThanBatchNumber deletes batches previous to the given batch number
+func (s *MemoryStorage) DeleteBatchesOlderThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		d.deleteBatches(func(n uint64) bool { return n < batchNumber })
+		return nil
+	})
+}
+
+// DeleteBatchesNewerThanBatchNumber deletes batches after the given batch number
+func (s *MemoryStorage) DeleteBatchesNewerThanBatchNumber(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		d.deleteBatches(func(n uint64) bool { return n > batchNumber })
+		return nil
+	})
+}
+
+// GetUnprovenBatchNumbers returns the numbers of the batches after the last
+// verified one not covered by a generated proof yet, in ascending order
+func (s *MemoryStorage) GetUnprovenBatchNumbers(ctx context.Context, lastVerifiedBatchNumber uint64, dbTx pgx.Tx) ([]uint64, error) {
+	batchNumbers := []uint64{}
+	err := s.read(dbTx, func(d *memData) error {
+		for batchNumber := range d.batches {
+			if batchNumber <= lastVerifiedBatchNumber || provenBatch(d, batchNumber) {
+				continue
+			}
+			batchNumbers = append(batchNumbers, batchNumber)
+		}
+		return nil
+	})
+	sort.Slice(batchNumbers, func(i, j int) bool { return batchNumbers[i] < batchNumbers[j] })
+	return batchNumbers, err
+}
+
+// provenBatch returns true if the batch is covered by a generated proof
+func provenBatch(d *memData, batchNumber uint64) bool {
+	for _, proof := range d.proofs {
+		if batchNumber >= proof.BatchNumber && batchNumber <= proof.BatchNumberFinal && proof.GeneratingSince == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSequence stores the sequence information to allow the aggregator verify sequences.
+func (s *MemoryStorage) AddSequence(ctx context.Context, sequence state.Sequence, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		if _, ok := d.batches[sequence.FromBatchNumber]; !ok {
+			return fmt.Errorf("batch %d of sequence %d-%d does not exist", sequence.FromBatchNumber, sequence.FromBatchNumber, sequence.ToBatchNumber)
+		}
+		d.sequences[sequence.FromBatchNumber] = sequence.ToBatchNumber
+		return nil
+	})
+}
+
+// GetBatches returns up to limit batches from the given batch number, in
+// ascending order
+func (s *MemoryStorage) GetBatches(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Batch, error) {
+	batches := []state.Batch{}
+	err := s.read(dbTx, func(d *memData) error {
+		for batchNumber, stored := range d.batches {
+			if batchNumber < fromBatchNumber {
+				continue
+			}
+			batch := stored.batch
+			batch.BatchL2Data = append([]byte(nil), stored.batch.BatchL2Data...)
+			batches = append(batches, batch)
+		}
+		return nil
+	})
+	sort.Slice(batches, func(i, j int) bool { return batches[i].BatchNumber < batches[j].BatchNumber })
+	if uint64(len(batches)) > limit {
+		batches = batches[:limit]
+	}
+	return batches, err
+}
+
+// GetSequences returns up to limit sequences ending at or after the given
+// batch number, in ascending order
+func (s *MemoryStorage) GetSequences(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Sequence, error) {
+	sequences := []state.Sequence{}
+	err := s.read(dbTx, func(d *memData) error {
+		for from, to := range d.sequences {
+			if to >= fromBatchNumber {
+				sequences = append(sequences, state.Sequence{FromBatchNumber: from, ToBatchNumber: to})
+			}
+		}
+		return nil
+	})
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i].FromBatchNumber < sequences[j].FromBatchNumber })
+	if uint64(len(sequences)) > limit {
+		sequences = sequences[:limit]
+	}
+	return sequences, err
+}
@@ -0,0 +1,102 @@
+package memstatestorage
+
+import (
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+)
+
+type proofKey struct {
+	batchNumber      uint64
+	batchNumberFinal uint64
+}
+
+type memBatch struct {
+	batch      state.Batch
+	datastream []byte
+}
+
+// memData are the tables of the state
+type memData struct {
+	batches      map[uint64]memBatch
+	proofs       map[proofKey]state.Proof
+	sequences    map[uint64]uint64
+	auditLog     []state.AuditLogEntry
+	batchStats   map[uint64]state.BatchStats
+	l1Intents    []state.L1Intent
+	failedProofs []state.FailedProof
+	publicInputs map[proofKey]state.FinalProofPublicInputs
+	// partitions of the proof table, by first batch number. Proofs are not
+	// kept apart, dropping a partition deletes the proofs in its range.
+	partitions map[uint64]uint64
+}
+
+func newMemData() *memData {
+	return &memData{
+		batches:      make(map[uint64]memBatch),
+		proofs:       make(map[proofKey]state.Proof),
+		sequences:    make(map[uint64]uint64),
+		batchStats:   make(map[uint64]state.BatchStats),
+		publicInputs: make(map[proofKey]state.FinalProofPublicInputs),
+		partitions:   make(map[uint64]uint64),
+	}
+}
+
+// clone returns a copy of the tables. The stored values are never modified
+// in place, so copying the containers is enough.
+func (d *memData) clone() *memData {
+	c := &memData{
+		batches:      make(map[uint64]memBatch, len(d.batches)),
+		proofs:       make(map[proofKey]state.Proof, len(d.proofs)),
+		sequences:    make(map[uint64]uint64, len(d.sequences)),
+		auditLog:     append([]state.AuditLogEntry(nil), d.auditLog...),
+		batchStats:   make(map[uint64]state.BatchStats, len(d.batchStats)),
+		l1Intents:    append([]state.L1Intent(nil), d.l1Intents...),
+		failedProofs: append([]state.FailedProof(nil), d.failedProofs...),
+		publicInputs: make(map[proofKey]state.FinalProofPublicInputs, len(d.publicInputs)),
+		partitions:   make(map[uint64]uint64, len(d.partitions)),
+	}
+	for k, v := range d.batches {
+		c.batches[k] = v
+	}
+	for k, v := range d.proofs {
+		c.proofs[k] = v
+	}
+	for k, v := range d.sequences {
+		c.sequences[k] = v
+	}
+	for k, v := range d.batchStats {
+		c.batchStats[k] = v
+	}
+	for k, v := range d.publicInputs {
+		c.publicInputs[k] = v
+	}
+	for k, v := range d.partitions {
+		c.partitions[k] = v
+	}
+	return c
+}
+
+// sequenceEnds returns the set of the last batch numbers of the sequences
+func (d *memData) sequenceEnds() map[uint64]bool {
+	ends := make(map[uint64]bool, len(d.sequences))
+	for _, to := range d.sequences {
+		ends[to] = true
+	}
+	return ends
+}
+
+// deleteBatches deletes the batches matching the condition along with the
+// proofs and sequences starting at them, as the foreign keys of the
+// Postgres schema do.
+func (d *memData) deleteBatches(match func(batchNumber uint64) bool) {
+	for batchNumber := range d.batches {
+		if match(batchNumber) {
+			delete(d.batches, batchNumber)
+			delete(d.sequences, batchNumber)
+		}
+	}
+	for key := range d.proofs {
+		if match(key.batchNumber) {
+			delete(d.proofs, key)
+		}
+	}
+}
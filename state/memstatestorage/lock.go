@@ -0,0 +1,61 @@
+package memstatestorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// lockPollInterval is how often LockBatchRange retries to acquire a lock held
+// by another transaction
+const lockPollInterval = 10 * time.Millisecond
+
+// LockBatchRange waits for the lock of the batch range in the given namespace.
+// The lock is held until dbTx ends.
+func (s *MemoryStorage) LockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) error {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		acquired, err := s.TryLockBatchRange(ctx, namespace, batchNumber, batchNumberFinal, dbTx)
+		if err != nil || acquired {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryLockBatchRange acquires the lock of the batch range in the given
+// namespace if no other transaction holds it, returning whether it has been
+// acquired. The lock is held until dbTx ends.
+func (s *MemoryStorage) TryLockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) (bool, error) {
+	tx, ok := dbTx.(*memTx)
+	if !ok {
+		return false, errNotMemoryTx
+	}
+	key := fmt.Sprintf("%s:%d-%d", namespace, batchNumber, batchNumberFinal)
+
+	s.locksMutex.Lock()
+	defer s.locksMutex.Unlock()
+	if holder, ok := s.locks[key]; ok {
+		return holder == tx, nil
+	}
+	s.locks[key] = tx
+	return true, nil
+}
+
+// releaseLocks releases the locks held by the transaction
+func (s *MemoryStorage) releaseLocks(tx *memTx) {
+	s.locksMutex.Lock()
+	defer s.locksMutex.Unlock()
+	for key, holder := range s.locks {
+		if holder == tx {
+			delete(s.locks, key)
+		}
+	}
+}
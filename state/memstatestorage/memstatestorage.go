@@ -0,0 +1,102 @@
+// Package memstatestorage is an in-memory implementation of the aggregator
+// state storage, meant for tests and development setups where running
+// Postgres is not worth it. Nothing is persisted across restarts.
+package memstatestorage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+var _ state.Storage = (*MemoryStorage)(nil)
+
+// ErrSQLNotSupported is returned when raw SQL is run against the in-memory
+// storage
+var ErrSQLNotSupported = errors.New("raw SQL is not supported by the in-memory storage")
+
+// errNotMemoryTx is returned when the storage is given a transaction it did
+// not begin
+var errNotMemoryTx = errors.New("transaction not begun by the in-memory storage")
+
+// MemoryStorage implements the state storage in memory
+type MemoryStorage struct {
+	cfg   state.Config
+	data  *memData
+	mutex sync.RWMutex
+
+	// ids of the records, allocated outside the transactions so a replayed
+	// write keeps the id returned to the caller
+	nextAuditLogID    atomic.Uint64
+	nextL1IntentID    atomic.Uint64
+	nextFailedProofID atomic.Uint64
+
+	// transactions holding the batch range locks, by lock key
+	locks      map[string]*memTx
+	locksMutex sync.Mutex
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage(cfg state.Config) *MemoryStorage {
+	return &MemoryStorage{
+		cfg:   cfg,
+		data:  newMemData(),
+		locks: make(map[string]*memTx),
+	}
+}
+
+// Begin starts a transaction. Its reads see the state when it began plus its
+// own writes, which are applied to the state when it commits.
+func (s *MemoryStorage) Begin(ctx context.Context) (pgx.Tx, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return &memTx{storage: s, data: s.data.clone()}, nil
+}
+
+// read runs fn on the data seen by dbTx, or on the committed data if dbTx is
+// nil.
+func (s *MemoryStorage) read(dbTx pgx.Tx, fn func(d *memData) error) error {
+	if dbTx == nil {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		return fn(s.data)
+	}
+	tx, ok := dbTx.(*memTx)
+	if !ok {
+		return errNotMemoryTx
+	}
+	return tx.run(fn, false)
+}
+
+// write runs fn on the committed data, or on the data of dbTx to be replayed
+// on the committed data when it commits.
+func (s *MemoryStorage) write(dbTx pgx.Tx, fn func(d *memData) error) error {
+	if dbTx == nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return fn(s.data)
+	}
+	tx, ok := dbTx.(*memTx)
+	if !ok {
+		return errNotMemoryTx
+	}
+	return tx.run(fn, true)
+}
+
+// now returns the current time with the precision of the Postgres timestamps
+func now() time.Time {
+	return time.Now().UTC().Round(time.Microsecond)
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...interface{}) error {
+	return r.err
+}
@@ -0,0 +1,146 @@
+package memstatestorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T, batches uint64, sequences ...state.Sequence) *MemoryStorage {
+	ctx := context.Background()
+	s := NewMemoryStorage(state.Config{})
+	for i := uint64(1); i <= batches; i++ {
+		require.NoError(t, s.AddBatch(ctx, &state.Batch{BatchNumber: i}, nil, nil))
+	}
+	for _, sequence := range sequences {
+		require.NoError(t, s.AddSequence(ctx, sequence, nil))
+	}
+	return s
+}
+
+func addProof(t *testing.T, s *MemoryStorage, batchNumber, batchNumberFinal uint64, generating bool) {
+	proof := &state.Proof{BatchNumber: batchNumber, BatchNumberFinal: batchNumberFinal, Proof: "proof"}
+	if generating {
+		now := time.Now()
+		proof.Proof = ""
+		proof.GeneratingSince = &now
+	}
+	require.NoError(t, s.AddGeneratedProof(context.Background(), proof, nil))
+}
+
+func TestGetProofsToAggregate(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, 6, state.Sequence{FromBatchNumber: 1, ToBatchNumber: 3}, state.Sequence{FromBatchNumber: 4, ToBatchNumber: 6})
+
+	addProof(t, s, 1, 1, false)
+	addProof(t, s, 2, 2, true)
+	addProof(t, s, 3, 3, false)
+	addProof(t, s, 4, 5, false)
+
+	// proofs crossing a sequence boundary are only aggregated when both
+	// cover complete sequences
+	_, _, err := s.GetProofsToAggregate(ctx, 0, nil)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	addProof(t, s, 6, 6, false)
+	proof1, proof2, err := s.GetProofsToAggregate(ctx, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), proof1.BatchNumber)
+	assert.Equal(t, uint64(6), proof2.BatchNumberFinal)
+
+	require.NoError(t, s.DeleteGeneratedProofs(ctx, 2, 2, nil))
+	addProof(t, s, 2, 2, false)
+	proof1, proof2, err = s.GetProofsToAggregate(ctx, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), proof1.BatchNumber)
+	assert.Equal(t, uint64(2), proof2.BatchNumber)
+
+	// the aggregation of 1 and 2 is the only one within 2 batches
+	proof1, _, err = s.GetProofsToAggregate(ctx, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), proof1.BatchNumber)
+	require.NoError(t, s.DeleteGeneratedProofs(ctx, 1, 2, nil))
+	_, _, err = s.GetProofsToAggregate(ctx, 2, nil)
+	require.ErrorIs(t, err, state.ErrNotFound)
+}
+
+func TestGetProofReadyToVerify(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, 4, state.Sequence{FromBatchNumber: 1, ToBatchNumber: 2}, state.Sequence{FromBatchNumber: 3, ToBatchNumber: 4})
+
+	addProof(t, s, 1, 1, false)
+	_, err := s.GetProofReadyToVerify(ctx, 0, nil)
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	addProof(t, s, 1, 2, false)
+	proof, err := s.GetProofReadyToVerify(ctx, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), proof.BatchNumberFinal)
+
+	complete, err := s.CheckProofContainsCompleteSequences(ctx, proof, nil)
+	require.NoError(t, err)
+	assert.True(t, complete)
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, 2)
+
+	dbTx, err := s.Begin(ctx)
+	require.NoError(t, err)
+	addProofTx := &state.Proof{BatchNumber: 1, BatchNumberFinal: 1, Proof: "proof"}
+	require.NoError(t, s.AddGeneratedProof(ctx, addProofTx, dbTx))
+
+	exists, err := s.CheckProofExistsForBatch(ctx, 1, dbTx)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = s.CheckProofExistsForBatch(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.False(t, exists, "uncommitted proof visible outside of the transaction")
+
+	require.NoError(t, dbTx.Rollback(ctx))
+	exists, err = s.CheckProofExistsForBatch(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	dbTx, err = s.Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddGeneratedProof(ctx, addProofTx, dbTx))
+	require.NoError(t, dbTx.Commit(ctx))
+	exists, err = s.CheckProofExistsForBatch(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// deleting a batch deletes its proofs, as the foreign keys of the schema
+	require.NoError(t, s.DeleteBatchesOlderThanBatchNumber(ctx, 2, nil))
+	exists, err = s.CheckProofExistsForBatch(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestBatchRangeLock(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, 0)
+
+	dbTx1, err := s.Begin(ctx)
+	require.NoError(t, err)
+	dbTx2, err := s.Begin(ctx)
+	require.NoError(t, err)
+
+	acquired, err := s.TryLockBatchRange(ctx, "aggregate", 1, 2, dbTx1)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	acquired, err = s.TryLockBatchRange(ctx, "aggregate", 1, 2, dbTx2)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, dbTx1.Commit(ctx))
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, s.LockBatchRange(waitCtx, "aggregate", 1, 2, dbTx2))
+	require.NoError(t, dbTx2.Rollback(ctx))
+}
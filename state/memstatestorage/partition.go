@@ -0,0 +1,55 @@
+package memstatestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// GetProofPartitions returns the batch range partitions of the proofs,
+// ordered by batch number
+func (s *MemoryStorage) GetProofPartitions(ctx context.Context, dbTx pgx.Tx) ([]state.ProofPartition, error) {
+	partitions := []state.ProofPartition{}
+	err := s.read(dbTx, func(d *memData) error {
+		for from, to := range d.partitions {
+			partitions = append(partitions, state.ProofPartition{FromBatchNumber: from, ToBatchNumber: to})
+		}
+		return nil
+	})
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].FromBatchNumber < partitions[j].FromBatchNumber })
+	return partitions, err
+}
+
+// AddProofPartition records the partition of the proofs starting in the batch
+// range [from, to). It must run in a transaction.
+func (s *MemoryStorage) AddProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error {
+	if dbTx == nil {
+		return state.ErrDBTxNil
+	}
+	return s.write(dbTx, func(d *memData) error {
+		for from, to := range d.partitions {
+			if partition.FromBatchNumber < to && from < partition.ToBatchNumber {
+				return fmt.Errorf("proof partition %d-%d overlaps partition %d-%d", partition.FromBatchNumber, partition.ToBatchNumber, from, to)
+			}
+		}
+		d.partitions[partition.FromBatchNumber] = partition.ToBatchNumber
+		return nil
+	})
+}
+
+// DropProofPartition drops the partition along with the proofs it holds
+func (s *MemoryStorage) DropProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		if to, ok := d.partitions[partition.FromBatchNumber]; !ok || to != partition.ToBatchNumber {
+			return nil
+		}
+		delete(d.partitions, partition.FromBatchNumber)
+		deleteProofs(d, func(p state.Proof) bool {
+			return p.BatchNumber >= partition.FromBatchNumber && p.BatchNumber < partition.ToBatchNumber
+		})
+		return nil
+	})
+}
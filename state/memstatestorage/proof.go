@@ -0,0 +1,269 @@
+package memstatestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// CheckProofExistsForBatch checks if the batch is already included in any proof
+func (s *MemoryStorage) CheckProofExistsForBatch(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) (bool, error) {
+	var exists bool
+	err := s.read(dbTx, func(d *memData) error {
+		for key := range d.proofs {
+			if batchNumber >= key.batchNumber && batchNumber <= key.batchNumberFinal {
+				exists = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+// CheckProofContainsCompleteSequences checks if a recursive proof contains complete sequences
+func (s *MemoryStorage) CheckProofContainsCompleteSequences(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) (bool, error) {
+	var complete bool
+	err := s.read(dbTx, func(d *memData) error {
+		_, startsSequence := d.sequences[proof.BatchNumber]
+		complete = startsSequence && d.sequenceEnds()[proof.BatchNumberFinal]
+		return nil
+	})
+	return complete, err
+}
+
+// GetProofReadyToVerify return the proof that is ready to verify
+func (s *MemoryStorage) GetProofReadyToVerify(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*state.Proof, error) {
+	var ready *state.Proof
+	err := s.read(dbTx, func(d *memData) error {
+		ends := d.sequenceEnds()
+		for _, proof := range sortedProofs(d) {
+			if proof.BatchNumber != lastVerfiedBatchNumber+1 || proof.GeneratingSince != nil {
+				continue
+			}
+			if _, ok := d.sequences[proof.BatchNumber]; ok && ends[proof.BatchNumberFinal] {
+				ready = &proof
+				return nil
+			}
+		}
+		return state.ErrNotFound
+	})
+	return ready, err
+}
+
+// GetProofByID returns the stored proof matching the given proof id
+func (s *MemoryStorage) GetProofByID(ctx context.Context, proofID string, dbTx pgx.Tx) (*state.Proof, error) {
+	var found *state.Proof
+	err := s.read(dbTx, func(d *memData) error {
+		for _, proof := range d.proofs {
+			if proof.ProofID != nil && *proof.ProofID == proofID {
+				found = &proof
+				return nil
+			}
+		}
+		return state.ErrNotFound
+	})
+	return found, err
+}
+
+// GetProofsToAggregate return the next to proof that it is possible to aggregate
+// into a proof of at most maxBatches batches, 0 meaning no limit
+func (s *MemoryStorage) GetProofsToAggregate(ctx context.Context, maxBatches uint64, dbTx pgx.Tx) (*state.Proof, *state.Proof, error) {
+	var proof1, proof2 *state.Proof
+	err := s.read(dbTx, func(d *memData) error {
+		ends := d.sequenceEnds()
+		// each pair either falls inside a single sequence or is made of two
+		// proofs of complete sequences
+		insideSequence := func(from, to uint64) bool {
+			for seqFrom, seqTo := range d.sequences {
+				if from >= seqFrom && to <= seqTo {
+					return true
+				}
+			}
+			return false
+		}
+		completeSequences := func(p *state.Proof) bool {
+			_, ok := d.sequences[p.BatchNumber]
+			return ok && ends[p.BatchNumberFinal]
+		}
+
+		proofs := sortedProofs(d)
+		for i := range proofs {
+			p1 := proofs[i]
+			if p1.GeneratingSince != nil {
+				continue
+			}
+			for j := range proofs {
+				p2 := proofs[j]
+				if p2.BatchNumber != p1.BatchNumberFinal+1 || p2.GeneratingSince != nil {
+					continue
+				}
+				if maxBatches > 0 && p2.BatchNumberFinal-p1.BatchNumber+1 > maxBatches {
+					continue
+				}
+				if insideSequence(p1.BatchNumber, p2.BatchNumberFinal) || (completeSequences(&p1) && completeSequences(&p2)) {
+					proof1, proof2 = &p1, &p2
+					return nil
+				}
+			}
+		}
+		return state.ErrNotFound
+	})
+	return proof1, proof2, err
+}
+
+// GetOldestProofToAggregate returns the generated proof above the last
+// verified batch that has been waiting the longest to be aggregated.
+func (s *MemoryStorage) GetOldestProofToAggregate(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*state.Proof, error) {
+	var oldest *state.Proof
+	err := s.read(dbTx, func(d *memData) error {
+		for _, proof := range d.proofs {
+			if proof.BatchNumber <= lastVerfiedBatchNumber || proof.GeneratingSince != nil {
+				continue
+			}
+			if oldest == nil || proof.UpdatedAt.Before(oldest.UpdatedAt) {
+				p := proof
+				oldest = &p
+			}
+		}
+		if oldest == nil {
+			return state.ErrNotFound
+		}
+		return nil
+	})
+	return oldest, err
+}
+
+// AddGeneratedProof adds a generated proof to the storage
+func (s *MemoryStorage) AddGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error {
+	stored := *proof
+	stored.CreatedAt = now()
+	stored.UpdatedAt = stored.CreatedAt
+	return s.write(dbTx, func(d *memData) error {
+		key := proofKey{stored.BatchNumber, stored.BatchNumberFinal}
+		if _, ok := d.proofs[key]; ok {
+			return fmt.Errorf("proof %d-%d already exists", stored.BatchNumber, stored.BatchNumberFinal)
+		}
+		if _, ok := d.batches[stored.BatchNumber]; !ok {
+			return fmt.Errorf("batch %d of proof %d-%d does not exist", stored.BatchNumber, stored.BatchNumber, stored.BatchNumberFinal)
+		}
+		d.proofs[key] = stored
+		return nil
+	})
+}
+
+// UpdateGeneratedProof updates a generated proof in the storage
+func (s *MemoryStorage) UpdateGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error {
+	updated := *proof
+	updated.UpdatedAt = now()
+	return s.write(dbTx, func(d *memData) error {
+		key := proofKey{updated.BatchNumber, updated.BatchNumberFinal}
+		stored, ok := d.proofs[key]
+		if !ok {
+			return nil
+		}
+		updated.CreatedAt = stored.CreatedAt
+		d.proofs[key] = updated
+		return nil
+	})
+}
+
+// DeleteGeneratedProofs deletes from the storage the generated proofs falling
+// inside the batch numbers range.
+func (s *MemoryStorage) DeleteGeneratedProofs(ctx context.Context, batchNumber uint64, batchNumberFinal uint64, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		deleteProofs(d, func(p state.Proof) bool {
+			return p.BatchNumber >= batchNumber && p.BatchNumberFinal <= batchNumberFinal
+		})
+		return nil
+	})
+}
+
+// CleanupGeneratedProofs deletes from the storage the generated proofs up to
+// the specified batch number included.
+func (s *MemoryStorage) CleanupGeneratedProofs(ctx context.Context, batchNumber uint64, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		deleteProofs(d, func(p state.Proof) bool { return p.BatchNumberFinal <= batchNumber })
+		return nil
+	})
+}
+
+// CleanupLockedProofs deletes from the storage the proofs locked in generating
+// state for more than the provided threshold.
+func (s *MemoryStorage) CleanupLockedProofs(ctx context.Context, duration string, dbTx pgx.Tx) (int64, error) {
+	threshold, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, state.ErrUnsupportedDuration
+	}
+	var deleted int64
+	err = s.write(dbTx, func(d *memData) error {
+		limit := time.Now().Add(-threshold)
+		deleted = deleteProofs(d, func(p state.Proof) bool {
+			return p.GeneratingSince != nil && p.GeneratingSince.Before(limit)
+		})
+		return nil
+	})
+	return deleted, err
+}
+
+// DeleteUngeneratedProofs deletes ungenerated proofs.
+// This method is meant to be use during aggregator boot-up sequence
+func (s *MemoryStorage) DeleteUngeneratedProofs(ctx context.Context, dbTx pgx.Tx) error {
+	return s.write(dbTx, func(d *memData) error {
+		deleteProofs(d, func(p state.Proof) bool { return p.GeneratingSince != nil })
+		return nil
+	})
+}
+
+// deleteProofs deletes the proofs matching the condition, returning how many
+// have been deleted
+func deleteProofs(d *memData, match func(p state.Proof) bool) int64 {
+	var deleted int64
+	for key, proof := range d.proofs {
+		if match(proof) {
+			delete(d.proofs, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// sortedProofs returns the proofs ordered by batch range
+func sortedProofs(d *memData) []state.Proof {
+	proofs := make([]state.Proof, 0, len(d.proofs))
+	for _, proof := range d.proofs {
+		proofs = append(proofs, proof)
+	}
+	sort.Slice(proofs, func(i, j int) bool {
+		if proofs[i].BatchNumber != proofs[j].BatchNumber {
+			return proofs[i].BatchNumber < proofs[j].BatchNumber
+		}
+		return proofs[i].BatchNumberFinal < proofs[j].BatchNumberFinal
+	})
+	return proofs
+}
+
+// GetProofs returns up to limit proofs ending at or after the given batch
+// number, in ascending order. The proofs and their inputs are not loaded.
+func (s *MemoryStorage) GetProofs(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Proof, error) {
+	proofs := []state.Proof{}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, proof := range sortedProofs(d) {
+			if proof.BatchNumberFinal < fromBatchNumber {
+				continue
+			}
+			if uint64(len(proofs)) == limit {
+				break
+			}
+			proof.Proof = ""
+			proof.InputProver = ""
+			proofs = append(proofs, proof)
+		}
+		return nil
+	})
+	return proofs, err
+}
@@ -0,0 +1,222 @@
+package memstatestorage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddAuditLogEntry stores an operator action
+func (s *MemoryStorage) AddAuditLogEntry(ctx context.Context, entry *state.AuditLogEntry, dbTx pgx.Tx) error {
+	entry.ID = s.nextAuditLogID.Add(1)
+	entry.CreatedAt = now()
+	stored := *entry
+	stored.Params = append([]byte(nil), entry.Params...)
+	return s.write(dbTx, func(d *memData) error {
+		d.auditLog = append(d.auditLog, stored)
+		return nil
+	})
+}
+
+// GetAuditLog returns the latest operator actions, newest first. If action is
+// not empty only the entries of that action are returned.
+func (s *MemoryStorage) GetAuditLog(ctx context.Context, action string, limit uint64, dbTx pgx.Tx) ([]state.AuditLogEntry, error) {
+	entries := []state.AuditLogEntry{}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, entry := range d.auditLog {
+			if action == "" || entry.Action == action {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	if uint64(len(entries)) > limit {
+		entries = entries[:limit]
+	}
+	return entries, err
+}
+
+// AddBatchStats stores the stats of a proven batch
+func (s *MemoryStorage) AddBatchStats(ctx context.Context, stats *state.BatchStats, dbTx pgx.Tx) error {
+	stored := *stats
+	stored.CreatedAt = now()
+	return s.write(dbTx, func(d *memData) error {
+		d.batchStats[stored.BatchNumber] = stored
+		return nil
+	})
+}
+
+// GetBatchStats returns the stats of the proven batches in the range [fromBatchNumber, toBatchNumber]
+func (s *MemoryStorage) GetBatchStats(ctx context.Context, fromBatchNumber, toBatchNumber uint64, dbTx pgx.Tx) ([]state.BatchStats, error) {
+	var batchStats []state.BatchStats
+	err := s.read(dbTx, func(d *memData) error {
+		for batchNumber, stats := range d.batchStats {
+			if batchNumber >= fromBatchNumber && batchNumber <= toBatchNumber {
+				batchStats = append(batchStats, stats)
+			}
+		}
+		return nil
+	})
+	sort.Slice(batchStats, func(i, j int) bool { return batchStats[i].BatchNumber < batchStats[j].BatchNumber })
+	return batchStats, err
+}
+
+// AddL1Intent stores the intent to send a verification tx to L1
+func (s *MemoryStorage) AddL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error {
+	intent.ID = s.nextL1IntentID.Add(1)
+	intent.CreatedAt = now()
+	intent.UpdatedAt = intent.CreatedAt
+	stored := *intent
+	return s.write(dbTx, func(d *memData) error {
+		d.l1Intents = append(d.l1Intents, stored)
+		return nil
+	})
+}
+
+// UpdateL1Intent updates the monitored tx id and the status of an intent
+func (s *MemoryStorage) UpdateL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error {
+	intent.UpdatedAt = now()
+	id, monitoredTxID, status, updatedAt := intent.ID, intent.MonitoredTxID, intent.Status, intent.UpdatedAt
+	return s.write(dbTx, func(d *memData) error {
+		for i := range d.l1Intents {
+			if d.l1Intents[i].ID == id {
+				d.l1Intents[i].MonitoredTxID = monitoredTxID
+				d.l1Intents[i].Status = status
+				d.l1Intents[i].UpdatedAt = updatedAt
+				return nil
+			}
+		}
+		return state.ErrNotFound
+	})
+}
+
+// UpdateL1IntentStatusByMonitoredTxID updates the status of the intent of the
+// given monitored tx
+func (s *MemoryStorage) UpdateL1IntentStatusByMonitoredTxID(ctx context.Context, monitoredTxID common.Hash, status state.L1IntentStatus, dbTx pgx.Tx) error {
+	updatedAt := now()
+	return s.write(dbTx, func(d *memData) error {
+		for i := range d.l1Intents {
+			if d.l1Intents[i].MonitoredTxID != nil && *d.l1Intents[i].MonitoredTxID == monitoredTxID {
+				d.l1Intents[i].Status = status
+				d.l1Intents[i].UpdatedAt = updatedAt
+			}
+		}
+		return nil
+	})
+}
+
+// GetUnresolvedL1Intents returns the intents that are pending or sent, oldest
+// first
+func (s *MemoryStorage) GetUnresolvedL1Intents(ctx context.Context, dbTx pgx.Tx) ([]state.L1Intent, error) {
+	intents := []state.L1Intent{}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, intent := range d.l1Intents {
+			if intent.Status == state.L1IntentPending || intent.Status == state.L1IntentSent {
+				intents = append(intents, intent)
+			}
+		}
+		return nil
+	})
+	sort.Slice(intents, func(i, j int) bool { return intents[i].ID < intents[j].ID })
+	return intents, err
+}
+
+// GetL1Intents returns up to limit intents ending at or after the given batch
+// number, in ascending order
+func (s *MemoryStorage) GetL1Intents(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.L1Intent, error) {
+	intents := []state.L1Intent{}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, intent := range d.l1Intents {
+			if intent.BatchNumberFinal >= fromBatchNumber {
+				intents = append(intents, intent)
+			}
+		}
+		return nil
+	})
+	sort.Slice(intents, func(i, j int) bool {
+		if intents[i].BatchNumber != intents[j].BatchNumber {
+			return intents[i].BatchNumber < intents[j].BatchNumber
+		}
+		return intents[i].ID < intents[j].ID
+	})
+	if uint64(len(intents)) > limit {
+		intents = intents[:limit]
+	}
+	return intents, err
+}
+
+// AddFailedProof stores the forensic record of a failed proof
+func (s *MemoryStorage) AddFailedProof(ctx context.Context, failedProof *state.FailedProof, dbTx pgx.Tx) error {
+	failedProof.ID = s.nextFailedProofID.Add(1)
+	failedProof.CreatedAt = now()
+	stored := *failedProof
+	return s.write(dbTx, func(d *memData) error {
+		d.failedProofs = append(d.failedProofs, stored)
+		return nil
+	})
+}
+
+// SetFailedProofExported records that a failed proof has been exported to the
+// object storage
+func (s *MemoryStorage) SetFailedProofExported(ctx context.Context, id uint64, dbTx pgx.Tx) error {
+	exportedAt := now()
+	return s.write(dbTx, func(d *memData) error {
+		for i := range d.failedProofs {
+			if d.failedProofs[i].ID == id {
+				d.failedProofs[i].ExportedAt = &exportedAt
+			}
+		}
+		return nil
+	})
+}
+
+// GetFailedProofs returns the latest failed proofs, newest first
+func (s *MemoryStorage) GetFailedProofs(ctx context.Context, limit uint64, dbTx pgx.Tx) ([]state.FailedProof, error) {
+	failedProofs := []state.FailedProof{}
+	err := s.read(dbTx, func(d *memData) error {
+		failedProofs = append(failedProofs, d.failedProofs...)
+		return nil
+	})
+	sort.Slice(failedProofs, func(i, j int) bool { return failedProofs[i].ID > failedProofs[j].ID })
+	if uint64(len(failedProofs)) > limit {
+		failedProofs = failedProofs[:limit]
+	}
+	return failedProofs, err
+}
+
+// AddFinalProofPublicInputs stores the public inputs of a final proof,
+// replacing the ones of a previous final proof of the same batch range
+func (s *MemoryStorage) AddFinalProofPublicInputs(ctx context.Context, publicInputs *state.FinalProofPublicInputs, dbTx pgx.Tx) error {
+	publicInputs.CreatedAt = now()
+	stored := *publicInputs
+	return s.write(dbTx, func(d *memData) error {
+		d.publicInputs[proofKey{stored.BatchNumber, stored.BatchNumberFinal}] = stored
+		return nil
+	})
+}
+
+// GetFinalProofPublicInputs returns the public inputs of the latest final
+// proofs, newest first. If batchNumber is not 0 only the ones of the final
+// proofs including that batch are returned.
+func (s *MemoryStorage) GetFinalProofPublicInputs(ctx context.Context, batchNumber uint64, limit uint64, dbTx pgx.Tx) ([]state.FinalProofPublicInputs, error) {
+	publicInputsList := []state.FinalProofPublicInputs{}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, publicInputs := range d.publicInputs {
+			if batchNumber == 0 || (publicInputs.BatchNumber <= batchNumber && publicInputs.BatchNumberFinal >= batchNumber) {
+				publicInputsList = append(publicInputsList, publicInputs)
+			}
+		}
+		return nil
+	})
+	sort.Slice(publicInputsList, func(i, j int) bool {
+		return publicInputsList[i].BatchNumberFinal > publicInputsList[j].BatchNumberFinal
+	})
+	if uint64(len(publicInputsList)) > limit {
+		publicInputsList = publicInputsList[:limit]
+	}
+	return publicInputsList, err
+}
@@ -0,0 +1,76 @@
+package memstatestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// ExportSnapshot reads the batches, sequences and proofs stored in the state
+func (s *MemoryStorage) ExportSnapshot(ctx context.Context, dbTx pgx.Tx) (*state.Snapshot, error) {
+	snapshot := &state.Snapshot{
+		Version:   state.SnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	err := s.read(dbTx, func(d *memData) error {
+		for _, stored := range d.batches {
+			batch := stored.batch
+			snapshot.Batches = append(snapshot.Batches, state.SnapshotBatch{Batch: &batch, Datastream: append([]byte(nil), stored.datastream...)})
+		}
+		for from, to := range d.sequences {
+			snapshot.Sequences = append(snapshot.Sequences, state.Sequence{FromBatchNumber: from, ToBatchNumber: to})
+		}
+		snapshot.Proofs = sortedProofs(d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snapshot.Batches, func(i, j int) bool {
+		return snapshot.Batches[i].Batch.BatchNumber < snapshot.Batches[j].Batch.BatchNumber
+	})
+	sort.Slice(snapshot.Sequences, func(i, j int) bool {
+		return snapshot.Sequences[i].FromBatchNumber < snapshot.Sequences[j].FromBatchNumber
+	})
+	return snapshot, nil
+}
+
+// ImportSnapshot stores the batches, sequences and proofs of a snapshot. The
+// state must be empty. Proofs being generated when the snapshot was taken are
+// skipped so they are generated again, and the ones locked to be aggregated
+// are imported unlocked.
+func (s *MemoryStorage) ImportSnapshot(ctx context.Context, snapshot *state.Snapshot, dbTx pgx.Tx) error {
+	if snapshot.Version != state.SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, state.SnapshotVersion)
+	}
+	return s.write(dbTx, func(d *memData) error {
+		if len(d.batches) > 0 {
+			return fmt.Errorf("state is not empty, %d batches found", len(d.batches))
+		}
+		for _, batch := range snapshot.Batches {
+			d.batches[batch.Batch.BatchNumber] = memBatch{batch: *batch.Batch, datastream: batch.Datastream}
+		}
+		for _, sequence := range snapshot.Sequences {
+			if _, ok := d.batches[sequence.FromBatchNumber]; !ok {
+				return fmt.Errorf("failed to import sequence %d-%d: batch not found", sequence.FromBatchNumber, sequence.ToBatchNumber)
+			}
+			d.sequences[sequence.FromBatchNumber] = sequence.ToBatchNumber
+		}
+		for _, proof := range snapshot.Proofs {
+			if proof.GeneratingSince != nil && proof.Proof == "" {
+				// the prover generating it is not connected to the new storage
+				continue
+			}
+			if _, ok := d.batches[proof.BatchNumber]; !ok {
+				return fmt.Errorf("failed to import proof %d-%d: batch not found", proof.BatchNumber, proof.BatchNumberFinal)
+			}
+			proof.GeneratingSince = nil
+			d.proofs[proofKey{proof.BatchNumber, proof.BatchNumberFinal}] = proof
+		}
+		return nil
+	})
+}
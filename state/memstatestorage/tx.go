@@ -0,0 +1,126 @@
+package memstatestorage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// memTx is a transaction of the in-memory storage. It works on a copy of the
+// data taken when it began and records its writes to replay them on the
+// committed data when it commits, so concurrent transactions do not
+// overwrite each other.
+type memTx struct {
+	storage *MemoryStorage
+	data    *memData
+	writes  []func(d *memData) error
+	closed  bool
+	mutex   sync.Mutex
+}
+
+func (tx *memTx) run(fn func(d *memData) error, write bool) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	if err := fn(tx.data); err != nil {
+		return err
+	}
+	if write {
+		tx.writes = append(tx.writes, fn)
+	}
+	return nil
+}
+
+// Begin is not supported, nested transactions are not needed by the state
+func (tx *memTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// BeginFunc is not supported, nested transactions are not needed by the state
+func (tx *memTx) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return ErrSQLNotSupported
+}
+
+// Commit replays the writes of the transaction on the committed data and
+// releases its locks
+func (tx *memTx) Commit(ctx context.Context) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	defer tx.storage.releaseLocks(tx)
+
+	tx.storage.mutex.Lock()
+	defer tx.storage.mutex.Unlock()
+	committed := tx.storage.data.clone()
+	for _, write := range tx.writes {
+		if err := write(committed); err != nil {
+			return pgx.ErrTxCommitRollback
+		}
+	}
+	tx.storage.data = committed
+	return nil
+}
+
+// Rollback discards the writes of the transaction and releases its locks
+func (tx *memTx) Rollback(ctx context.Context) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	tx.storage.releaseLocks(tx)
+	return nil
+}
+
+// CopyFrom is not supported, raw SQL can not be run in memory
+func (tx *memTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, ErrSQLNotSupported
+}
+
+// SendBatch is not supported, raw SQL can not be run in memory
+func (tx *memTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return nil
+}
+
+// LargeObjects is not supported by the in-memory storage
+func (tx *memTx) LargeObjects() pgx.LargeObjects {
+	return pgx.LargeObjects{}
+}
+
+// Prepare is not supported, raw SQL can not be run in memory
+func (tx *memTx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// Exec is not supported, raw SQL can not be run in memory
+func (tx *memTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// Query is not supported, raw SQL can not be run in memory
+func (tx *memTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// QueryRow is not supported, raw SQL can not be run in memory
+func (tx *memTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return errRow{ErrSQLNotSupported}
+}
+
+// QueryFunc is not supported, raw SQL can not be run in memory
+func (tx *memTx) QueryFunc(ctx context.Context, sql string, args []interface{}, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// Conn returns nil, there is no connection behind the in-memory storage
+func (tx *memTx) Conn() *pgx.Conn {
+	return nil
+}
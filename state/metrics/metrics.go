@@ -15,8 +15,14 @@ const (
 	Prefix = "state_"
 	// ExecutorProcessingTimeName is the name of the metric that shows the processing time in the executor.
 	ExecutorProcessingTimeName = Prefix + "executor_processing_time"
+	// QueryDurationName is the name of the metric that shows the duration of the storage queries.
+	QueryDurationName = Prefix + "query_duration_seconds"
+	// SlowQueriesName is the name of the metric that counts the storage queries exceeding the slow query threshold.
+	SlowQueriesName = Prefix + "slow_queries"
 	// CallerLabelName is the name of the label for the caller.
 	CallerLabelName = "caller"
+	// StatementLabelName is the name of the label for the storage method running a query.
+	StatementLabelName = "statement"
 
 	// SequencerCallerLabel is used when sequencer is calling the function
 	SequencerCallerLabel CallerLabel = "sequencer"
@@ -36,9 +42,28 @@ func Register() {
 			},
 			Labels: []string{CallerLabelName},
 		},
+		{
+			HistogramOpts: prometheus.HistogramOpts{
+				Name:    QueryDurationName,
+				Help:    "[STATE] duration of the storage queries",
+				Buckets: prometheus.ExponentialBuckets(0.001, 4, 8), //nolint:gomnd
+			},
+			Labels: []string{StatementLabelName},
+		},
+	}
+
+	counterVecs := []metrics.CounterVecOpts{
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: SlowQueriesName,
+				Help: "[STATE] storage queries exceeding the slow query threshold",
+			},
+			Labels: []string{StatementLabelName},
+		},
 	}
 
 	metrics.RegisterHistogramVecs(histogramVecs...)
+	metrics.RegisterCounterVecs(counterVecs...)
 }
 
 // ExecutorProcessingTime observes the last processing time of the executor in the histogram vector by the provided elapsed time
@@ -47,3 +72,15 @@ func ExecutorProcessingTime(caller string, lastExecutionTime time.Duration) {
 	execTimeInSeconds := float64(lastExecutionTime) / float64(time.Second)
 	metrics.HistogramVecObserve(ExecutorProcessingTimeName, caller, execTimeInSeconds)
 }
+
+// QueryDuration observes the duration of a query run by the given storage
+// method.
+func QueryDuration(statement string, elapsed time.Duration) {
+	metrics.HistogramVecObserve(QueryDurationName, statement, elapsed.Seconds())
+}
+
+// SlowQuery increments the counter of slow queries run by the given storage
+// method.
+func SlowQuery(statement string) {
+	metrics.CounterVecInc(SlowQueriesName, statement)
+}
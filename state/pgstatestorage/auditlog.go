@@ -0,0 +1,53 @@
+package pgstatestorage
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddAuditLogEntry stores an operator action
+func (p *PostgresStorage) AddAuditLogEntry(ctx context.Context, entry *state.AuditLogEntry, dbTx pgx.Tx) error {
+	const addAuditLogEntrySQL = "INSERT INTO aggregator.audit_log (action, principal, params, result) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+
+	var params []byte
+	if len(entry.Params) > 0 {
+		params = entry.Params
+	}
+
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, addAuditLogEntrySQL, entry.Action, entry.Principal, params, entry.Result).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetAuditLog returns the latest operator actions, newest first. If action is
+// not empty only the entries of that action are returned.
+func (p *PostgresStorage) GetAuditLog(ctx context.Context, action string, limit uint64, dbTx pgx.Tx) ([]state.AuditLogEntry, error) {
+	const getAuditLogSQL = `
+		SELECT id, action, principal, params, result, created_at
+		FROM aggregator.audit_log
+		WHERE $1 = '' OR action = $1
+		ORDER BY id DESC
+		LIMIT $2
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getAuditLogSQL, action, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []state.AuditLogEntry{}
+	for rows.Next() {
+		var (
+			entry  state.AuditLogEntry
+			params []byte
+		)
+		if err := rows.Scan(&entry.ID, &entry.Action, &entry.Principal, &params, &entry.Result, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.Params = params
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -2,6 +2,7 @@ package pgstatestorage
 
 import (
 	"context"
+	"errors"
 
 	"github.com/0xPolygonHermez/zkevm-aggregator/state"
 	"github.com/ethereum/go-ethereum/common"
@@ -23,7 +24,9 @@ func (p *PostgresStorage) GetBatch(ctx context.Context, batchNumber uint64, dbTx
 	var batch *state.Batch
 	var streamStr string
 	err := e.QueryRow(ctx, getInputHashSQL, batchNumber).Scan(&batch, &streamStr)
-	if err != nil {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, state.ErrNotFound
+	} else if err != nil {
 		return nil, nil, err
 	}
 	return batch, common.Hex2Bytes(streamStr), nil
@@ -44,3 +47,55 @@ func (p *PostgresStorage) DeleteBatchesNewerThanBatchNumber(ctx context.Context,
 	_, err := e.Exec(ctx, deleteBatchesSQL, batchNumber)
 	return err
 }
+
+// GetUnprovenBatchNumbers returns the numbers of the batches after the last
+// verified one not covered by a generated proof yet, in ascending order
+func (p *PostgresStorage) GetUnprovenBatchNumbers(ctx context.Context, lastVerifiedBatchNumber uint64, dbTx pgx.Tx) ([]uint64, error) {
+	const getUnprovenBatchNumbersSQL = `
+		SELECT b.batch_num
+		FROM aggregator.batch b
+		WHERE b.batch_num > $1 AND NOT EXISTS (
+			SELECT 1 FROM aggregator.proof p
+			WHERE b.batch_num >= p.batch_num AND b.batch_num <= p.batch_num_final AND p.proof IS NOT NULL
+		)
+		ORDER BY b.batch_num
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getUnprovenBatchNumbersSQL, lastVerifiedBatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batchNumbers := []uint64{}
+	for rows.Next() {
+		var batchNumber uint64
+		if err := rows.Scan(&batchNumber); err != nil {
+			return nil, err
+		}
+		batchNumbers = append(batchNumbers, batchNumber)
+	}
+	return batchNumbers, rows.Err()
+}
+
+// GetBatches returns up to limit batches from the given batch number, in
+// ascending order
+func (p *PostgresStorage) GetBatches(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Batch, error) {
+	const getBatchesSQL = "SELECT batch FROM aggregator.batch WHERE batch_num >= $1 ORDER BY batch_num ASC LIMIT $2"
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getBatchesSQL, fromBatchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := []state.Batch{}
+	for rows.Next() {
+		var batch state.Batch
+		if err := rows.Scan(&batch); err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, rows.Err()
+}
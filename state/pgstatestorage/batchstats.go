@@ -0,0 +1,55 @@
+package pgstatestorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddBatchStats stores the stats of a proven batch
+func (p *PostgresStorage) AddBatchStats(ctx context.Context, stats *state.BatchStats, dbTx pgx.Tx) error {
+	const addBatchStatsSQL = `
+		INSERT INTO aggregator.batch_stats (batch_num, witness_size, l2_data_size, block_count, tx_count, gas_limit, proving_time_ms, prover)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (batch_num) DO UPDATE SET
+			witness_size = $2, l2_data_size = $3, block_count = $4, tx_count = $5, gas_limit = $6, proving_time_ms = $7, prover = $8, created_at = now()
+		`
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, addBatchStatsSQL, stats.BatchNumber, stats.WitnessSize, stats.L2DataSize, stats.BlockCount, stats.TxCount,
+		stats.GasLimit, stats.ProvingTime.Milliseconds(), stats.Prover)
+	return err
+}
+
+// GetBatchStats returns the stats of the proven batches in the range [fromBatchNumber, toBatchNumber]
+func (p *PostgresStorage) GetBatchStats(ctx context.Context, fromBatchNumber, toBatchNumber uint64, dbTx pgx.Tx) ([]state.BatchStats, error) {
+	const getBatchStatsSQL = `
+		SELECT batch_num, witness_size, l2_data_size, block_count, tx_count, gas_limit, proving_time_ms, COALESCE(prover, ''), created_at
+		FROM aggregator.batch_stats
+		WHERE batch_num >= $1 AND batch_num <= $2
+		ORDER BY batch_num
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getBatchStatsSQL, fromBatchNumber, toBatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batchStats []state.BatchStats
+	for rows.Next() {
+		var (
+			stats         state.BatchStats
+			provingTimeMs int64
+		)
+		err := rows.Scan(&stats.BatchNumber, &stats.WitnessSize, &stats.L2DataSize, &stats.BlockCount, &stats.TxCount,
+			&stats.GasLimit, &provingTimeMs, &stats.Prover, &stats.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		stats.ProvingTime = time.Duration(provingTimeMs) * time.Millisecond
+		batchStats = append(batchStats, stats)
+	}
+	return batchStats, rows.Err()
+}
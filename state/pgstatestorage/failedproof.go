@@ -0,0 +1,57 @@
+package pgstatestorage
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddFailedProof stores the forensic record of a failed proof
+func (p *PostgresStorage) AddFailedProof(ctx context.Context, failedProof *state.FailedProof, dbTx pgx.Tx) error {
+	const addFailedProofSQL = `
+		INSERT INTO aggregator.failed_proofs (kind, batch_num, batch_num_final, proof_id, prover, prover_id, input_prover, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+		`
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, addFailedProofSQL, failedProof.Kind, failedProof.BatchNumber, failedProof.BatchNumberFinal, failedProof.ProofID,
+		failedProof.Prover, failedProof.ProverID, failedProof.InputProver, failedProof.Error).Scan(&failedProof.ID, &failedProof.CreatedAt)
+}
+
+// SetFailedProofExported records that a failed proof has been exported to the
+// object storage
+func (p *PostgresStorage) SetFailedProofExported(ctx context.Context, id uint64, dbTx pgx.Tx) error {
+	const setFailedProofExportedSQL = "UPDATE aggregator.failed_proofs SET exported_at = now() WHERE id = $1"
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, setFailedProofExportedSQL, id)
+	return err
+}
+
+// GetFailedProofs returns the latest failed proofs, newest first
+func (p *PostgresStorage) GetFailedProofs(ctx context.Context, limit uint64, dbTx pgx.Tx) ([]state.FailedProof, error) {
+	const getFailedProofsSQL = `
+		SELECT id, kind, batch_num, batch_num_final, proof_id, prover, prover_id, input_prover, error, exported_at, created_at
+		FROM aggregator.failed_proofs
+		ORDER BY id DESC
+		LIMIT $1
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getFailedProofsSQL, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failedProofs := []state.FailedProof{}
+	for rows.Next() {
+		var failedProof state.FailedProof
+		err := rows.Scan(&failedProof.ID, &failedProof.Kind, &failedProof.BatchNumber, &failedProof.BatchNumberFinal, &failedProof.ProofID,
+			&failedProof.Prover, &failedProof.ProverID, &failedProof.InputProver, &failedProof.Error, &failedProof.ExportedAt, &failedProof.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		failedProofs = append(failedProofs, failedProof)
+	}
+	return failedProofs, rows.Err()
+}
@@ -0,0 +1,61 @@
+package pgstatestorage
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/log"
+	"github.com/0xPolygonHermez/zkevm-aggregator/state/metrics"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// instrumentedExecQuerier measures the queries run by a storage method,
+// logging the ones exceeding the slow query threshold. The duration of Query
+// covers the time until the first rows are available, not their iteration.
+type instrumentedExecQuerier struct {
+	ExecQuerier
+	statement          string
+	slowQueryThreshold time.Duration
+}
+
+func (e *instrumentedExecQuerier) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	defer e.observe(time.Now(), sql)
+	return e.ExecQuerier.Exec(ctx, sql, arguments...)
+}
+
+func (e *instrumentedExecQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	defer e.observe(time.Now(), sql)
+	return e.ExecQuerier.Query(ctx, sql, args...)
+}
+
+func (e *instrumentedExecQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	defer e.observe(time.Now(), sql)
+	return e.ExecQuerier.QueryRow(ctx, sql, args...)
+}
+
+func (e *instrumentedExecQuerier) observe(start time.Time, sql string) {
+	elapsed := time.Since(start)
+	metrics.QueryDuration(e.statement, elapsed)
+	if e.slowQueryThreshold > 0 && elapsed >= e.slowQueryThreshold {
+		metrics.SlowQuery(e.statement)
+		log.Warnf("Slow query in %s took %v: %s", e.statement, elapsed, strings.Join(strings.Fields(sql), " "))
+	}
+}
+
+// callerName returns the name of the method calling the function that calls
+// callerName, e.g. GetProofsToAggregate.
+func callerName() string {
+	pc, _, _, ok := runtime.Caller(2) //nolint:gomnd
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,106 @@
+package pgstatestorage
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddL1Intent stores the intent to send a verification tx to L1
+func (p *PostgresStorage) AddL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error {
+	const addL1IntentSQL = `
+		INSERT INTO aggregator.l1_intent (batch_num, batch_num_final, calldata_hash, nonce, monitored_tx_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+		`
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, addL1IntentSQL, intent.BatchNumber, intent.BatchNumberFinal, intent.CalldataHash.String(),
+		intent.Nonce, monitoredTxIDToString(intent.MonitoredTxID), intent.Status).Scan(&intent.ID, &intent.CreatedAt, &intent.UpdatedAt)
+}
+
+// UpdateL1Intent updates the monitored tx id and the status of an intent
+func (p *PostgresStorage) UpdateL1Intent(ctx context.Context, intent *state.L1Intent, dbTx pgx.Tx) error {
+	const updateL1IntentSQL = "UPDATE aggregator.l1_intent SET monitored_tx_id = $2, status = $3, updated_at = now() WHERE id = $1 RETURNING updated_at"
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, updateL1IntentSQL, intent.ID, monitoredTxIDToString(intent.MonitoredTxID), intent.Status).Scan(&intent.UpdatedAt)
+}
+
+// UpdateL1IntentStatusByMonitoredTxID updates the status of the intent of the
+// given monitored tx
+func (p *PostgresStorage) UpdateL1IntentStatusByMonitoredTxID(ctx context.Context, monitoredTxID common.Hash, status state.L1IntentStatus, dbTx pgx.Tx) error {
+	const updateL1IntentStatusSQL = "UPDATE aggregator.l1_intent SET status = $2, updated_at = now() WHERE monitored_tx_id = $1"
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, updateL1IntentStatusSQL, monitoredTxID.String(), status)
+	return err
+}
+
+// GetUnresolvedL1Intents returns the intents that are pending or sent, oldest
+// first
+func (p *PostgresStorage) GetUnresolvedL1Intents(ctx context.Context, dbTx pgx.Tx) ([]state.L1Intent, error) {
+	const getUnresolvedL1IntentsSQL = `
+		SELECT id, batch_num, batch_num_final, calldata_hash, nonce, monitored_tx_id, status, created_at, updated_at
+		FROM aggregator.l1_intent
+		WHERE status IN ($1, $2)
+		ORDER BY id ASC
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getUnresolvedL1IntentsSQL, state.L1IntentPending, state.L1IntentSent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanL1Intents(rows)
+}
+
+// GetL1Intents returns up to limit intents ending at or after the given batch
+// number, in ascending order
+func (p *PostgresStorage) GetL1Intents(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.L1Intent, error) {
+	const getL1IntentsSQL = `
+		SELECT id, batch_num, batch_num_final, calldata_hash, nonce, monitored_tx_id, status, created_at, updated_at
+		FROM aggregator.l1_intent
+		WHERE batch_num_final >= $1
+		ORDER BY batch_num ASC, id ASC
+		LIMIT $2
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getL1IntentsSQL, fromBatchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanL1Intents(rows)
+}
+
+// scanL1Intents reads the intents returned by a query of all their columns
+func scanL1Intents(rows pgx.Rows) ([]state.L1Intent, error) {
+	intents := []state.L1Intent{}
+	for rows.Next() {
+		var (
+			intent        state.L1Intent
+			calldataHash  string
+			monitoredTxID *string
+		)
+		err := rows.Scan(&intent.ID, &intent.BatchNumber, &intent.BatchNumberFinal, &calldataHash, &intent.Nonce,
+			&monitoredTxID, &intent.Status, &intent.CreatedAt, &intent.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		intent.CalldataHash = common.HexToHash(calldataHash)
+		if monitoredTxID != nil {
+			id := common.HexToHash(*monitoredTxID)
+			intent.MonitoredTxID = &id
+		}
+		intents = append(intents, intent)
+	}
+	return intents, rows.Err()
+}
+
+func monitoredTxIDToString(monitoredTxID *common.Hash) *string {
+	if monitoredTxID == nil {
+		return nil
+	}
+	id := monitoredTxID.String()
+	return &id
+}
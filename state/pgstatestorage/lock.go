@@ -0,0 +1,36 @@
+package pgstatestorage
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// LockBatchRange waits for the advisory lock of the batch range in the given
+// namespace. The lock is held until dbTx ends, so it is released even if the
+// aggregator holding it crashes.
+func (p *PostgresStorage) LockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) error {
+	const lockBatchRangeSQL = "SELECT pg_advisory_xact_lock($1)"
+	_, err := dbTx.Exec(ctx, lockBatchRangeSQL, batchRangeLockKey(namespace, batchNumber, batchNumberFinal))
+	return err
+}
+
+// TryLockBatchRange acquires the advisory lock of the batch range in the given
+// namespace if no other transaction holds it, returning whether it has been
+// acquired. The lock is held until dbTx ends.
+func (p *PostgresStorage) TryLockBatchRange(ctx context.Context, namespace string, batchNumber, batchNumberFinal uint64, dbTx pgx.Tx) (bool, error) {
+	const tryLockBatchRangeSQL = "SELECT pg_try_advisory_xact_lock($1)"
+	var acquired bool
+	err := dbTx.QueryRow(ctx, tryLockBatchRangeSQL, batchRangeLockKey(namespace, batchNumber, batchNumberFinal)).Scan(&acquired)
+	return acquired, err
+}
+
+// batchRangeLockKey maps a batch range in a namespace to the 64 bits key of a
+// postgres advisory lock.
+func batchRangeLockKey(namespace string, batchNumber, batchNumberFinal uint64) int64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "aggregator:%s:%d-%d", namespace, batchNumber, batchNumberFinal)
+	return int64(h.Sum64())
+}
@@ -0,0 +1,89 @@
+package pgstatestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// proofPartitionName is the name of the partition of the proof table holding
+// the proofs starting in the batch range [from, to)
+func proofPartitionName(from, to uint64) string {
+	return fmt.Sprintf("proof_p%d_%d", from, to)
+}
+
+// GetProofPartitions returns the batch range partitions of the proof table,
+// ordered by batch number. The default partition is not included.
+func (p *PostgresStorage) GetProofPartitions(ctx context.Context, dbTx pgx.Tx) ([]state.ProofPartition, error) {
+	const getProofPartitionsSQL = `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class t ON t.oid = i.inhparent
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = 'aggregator' AND t.relname = 'proof'
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getProofPartitionsSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := []state.ProofPartition{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		var partition state.ProofPartition
+		if _, err := fmt.Sscanf(name, "proof_p%d_%d", &partition.FromBatchNumber, &partition.ToBatchNumber); err != nil {
+			// the default partition or one not created by the aggregator
+			continue
+		}
+		partitions = append(partitions, partition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].FromBatchNumber < partitions[j].FromBatchNumber })
+	return partitions, nil
+}
+
+// AddProofPartition creates the partition of the proof table for the proofs
+// starting in the batch range [from, to), moving into it the ones stored in
+// the default partition. It must run in a transaction.
+func (p *PostgresStorage) AddProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error {
+	if dbTx == nil {
+		return state.ErrDBTxNil
+	}
+	name := proofPartitionName(partition.FromBatchNumber, partition.ToBatchNumber)
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE aggregator.%s (LIKE aggregator.proof INCLUDING DEFAULTS)", name),
+		fmt.Sprintf("INSERT INTO aggregator.%s SELECT * FROM aggregator.proof_default WHERE batch_num >= %d AND batch_num < %d",
+			name, partition.FromBatchNumber, partition.ToBatchNumber),
+		fmt.Sprintf("DELETE FROM aggregator.proof_default WHERE batch_num >= %d AND batch_num < %d",
+			partition.FromBatchNumber, partition.ToBatchNumber),
+		fmt.Sprintf("ALTER TABLE aggregator.proof ATTACH PARTITION aggregator.%s FOR VALUES FROM (%d) TO (%d)",
+			name, partition.FromBatchNumber, partition.ToBatchNumber),
+	}
+	e := p.getExecQuerier(dbTx)
+	for _, sql := range statements {
+		if _, err := e.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to create proof partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DropProofPartition drops the partition of the proof table along with the
+// proofs it holds
+func (p *PostgresStorage) DropProofPartition(ctx context.Context, partition state.ProofPartition, dbTx pgx.Tx) error {
+	name := proofPartitionName(partition.FromBatchNumber, partition.ToBatchNumber)
+	e := p.getExecQuerier(dbTx)
+	_, err := e.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS aggregator.%s", name))
+	return err
+}
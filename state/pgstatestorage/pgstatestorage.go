@@ -6,6 +6,8 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+var _ state.Storage = (*PostgresStorage)(nil)
+
 // PostgresStorage implements the Storage interface
 type PostgresStorage struct {
 	cfg state.Config
@@ -20,10 +22,17 @@ func NewPostgresStorage(cfg state.Config, db *pgxpool.Pool) *PostgresStorage {
 	}
 }
 
-// getExecQuerier determines which execQuerier to use, dbTx or the main pgxpool
+// getExecQuerier determines which execQuerier to use, dbTx or the main pgxpool.
+// The queries run through it are measured and labeled with the name of the
+// storage method calling getExecQuerier.
 func (p *PostgresStorage) getExecQuerier(dbTx pgx.Tx) ExecQuerier {
+	var e ExecQuerier = p.Pool
 	if dbTx != nil {
-		return dbTx
+		e = dbTx
+	}
+	return &instrumentedExecQuerier{
+		ExecQuerier:        e,
+		statement:          callerName(),
+		slowQueryThreshold: p.cfg.DB.SlowQueryThreshold.Duration,
 	}
-	return p
 }
@@ -50,6 +50,7 @@ func (p *PostgresStorage) GetProofReadyToVerify(ctx context.Context, lastVerfied
 			p.input_prover,
 			p.prover,
 			p.prover_id,
+			p.prover_version,
 			p.generating_since,
 			p.created_at,
 			p.updated_at
@@ -63,7 +64,41 @@ func (p *PostgresStorage) GetProofReadyToVerify(ctx context.Context, lastVerfied
 
 	e := p.getExecQuerier(dbTx)
 	row := e.QueryRow(ctx, getProofReadyToVerifySQL, lastVerfiedBatchNumber+1)
-	err := row.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proof.Proof, &proof.ProofID, &proof.InputProver, &proof.Prover, &proof.ProverID, &proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
+	err := row.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proof.Proof, &proof.ProofID, &proof.InputProver, &proof.Prover, &proof.ProverID, &proof.ProverVersion, &proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, state.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return proof, err
+}
+
+// GetProofByID returns the stored proof matching the given proof id
+func (p *PostgresStorage) GetProofByID(ctx context.Context, proofID string, dbTx pgx.Tx) (*state.Proof, error) {
+	const getProofByIDSQL = `
+		SELECT 
+			p.batch_num, 
+			p.batch_num_final,
+			p.proof,
+			p.proof_id,
+			p.input_prover,
+			p.prover,
+			p.prover_id,
+			p.prover_version,
+			p.generating_since,
+			p.created_at,
+			p.updated_at
+		FROM aggregator.proof p
+		WHERE p.proof_id = $1
+		`
+
+	var proof *state.Proof = &state.Proof{}
+
+	e := p.getExecQuerier(dbTx)
+	row := e.QueryRow(ctx, getProofByIDSQL, proofID)
+	err := row.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proof.Proof, &proof.ProofID, &proof.InputProver, &proof.Prover, &proof.ProverID, &proof.ProverVersion, &proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, state.ErrNotFound
@@ -75,7 +110,8 @@ func (p *PostgresStorage) GetProofReadyToVerify(ctx context.Context, lastVerfied
 }
 
 // GetProofsToAggregate return the next to proof that it is possible to aggregate
-func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx) (*state.Proof, *state.Proof, error) {
+// into a proof of at most maxBatches batches, 0 meaning no limit
+func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, maxBatches uint64, dbTx pgx.Tx) (*state.Proof, *state.Proof, error) {
 	var (
 		proof1 *state.Proof = &state.Proof{}
 		proof2 *state.Proof = &state.Proof{}
@@ -91,6 +127,7 @@ func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx)
 			p1.input_prover as p1_input_prover, 
 			p1.prover as p1_prover,
 			p1.prover_id as p1_prover_id,
+			p1.prover_version as p1_prover_version,
 			p1.generating_since as p1_generating_since,
 			p1.created_at as p1_created_at,
 			p1.updated_at as p1_updated_at,
@@ -101,6 +138,7 @@ func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx)
 			p2.input_prover as p2_input_prover, 
 			p2.prover as p2_prover,
 			p2.prover_id as p2_prover_id,
+			p2.prover_version as p2_prover_version,
 			p2.generating_since as p2_generating_since,
 			p2.created_at as p2_created_at,
 			p2.updated_at as p2_updated_at
@@ -122,16 +160,17 @@ func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx)
 						EXISTS ( SELECT 1 FROM aggregator.sequence s WHERE p2.batch_num = s.from_batch_num) AND
 						EXISTS ( SELECT 1 FROM aggregator.sequence s WHERE p2.batch_num_final = s.to_batch_num)
 					)
-				)
+				) AND
+			  ($1::BIGINT = 0 OR p2.batch_num_final - p1.batch_num + 1 <= $1::BIGINT)
 		ORDER BY p1.batch_num ASC
 		LIMIT 1
 		`
 
 	e := p.getExecQuerier(dbTx)
-	row := e.QueryRow(ctx, getProofsToAggregateSQL)
+	row := e.QueryRow(ctx, getProofsToAggregateSQL, maxBatches)
 	err := row.Scan(
-		&proof1.BatchNumber, &proof1.BatchNumberFinal, &proof1.Proof, &proof1.ProofID, &proof1.InputProver, &proof1.Prover, &proof1.ProverID, &proof1.GeneratingSince, &proof1.CreatedAt, &proof1.UpdatedAt,
-		&proof2.BatchNumber, &proof2.BatchNumberFinal, &proof2.Proof, &proof2.ProofID, &proof2.InputProver, &proof2.Prover, &proof2.ProverID, &proof2.GeneratingSince, &proof2.CreatedAt, &proof2.UpdatedAt)
+		&proof1.BatchNumber, &proof1.BatchNumberFinal, &proof1.Proof, &proof1.ProofID, &proof1.InputProver, &proof1.Prover, &proof1.ProverID, &proof1.ProverVersion, &proof1.GeneratingSince, &proof1.CreatedAt, &proof1.UpdatedAt,
+		&proof2.BatchNumber, &proof2.BatchNumberFinal, &proof2.Proof, &proof2.ProofID, &proof2.InputProver, &proof2.Prover, &proof2.ProverID, &proof2.ProverVersion, &proof2.GeneratingSince, &proof2.CreatedAt, &proof2.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil, state.ErrNotFound
@@ -144,19 +183,19 @@ func (p *PostgresStorage) GetProofsToAggregate(ctx context.Context, dbTx pgx.Tx)
 
 // AddGeneratedProof adds a generated proof to the storage
 func (p *PostgresStorage) AddGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error {
-	const addGeneratedProofSQL = "INSERT INTO aggregator.proof (batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, generating_since, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	const addGeneratedProofSQL = "INSERT INTO aggregator.proof (batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, prover_version, generating_since, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
 	e := p.getExecQuerier(dbTx)
 	now := time.Now().UTC().Round(time.Microsecond)
-	_, err := e.Exec(ctx, addGeneratedProofSQL, proof.BatchNumber, proof.BatchNumberFinal, proof.Proof, proof.ProofID, proof.InputProver, proof.Prover, proof.ProverID, proof.GeneratingSince, now, now)
+	_, err := e.Exec(ctx, addGeneratedProofSQL, proof.BatchNumber, proof.BatchNumberFinal, proof.Proof, proof.ProofID, proof.InputProver, proof.Prover, proof.ProverID, proof.ProverVersion, proof.GeneratingSince, now, now)
 	return err
 }
 
 // UpdateGeneratedProof updates a generated proof in the storage
 func (p *PostgresStorage) UpdateGeneratedProof(ctx context.Context, proof *state.Proof, dbTx pgx.Tx) error {
-	const addGeneratedProofSQL = "UPDATE aggregator.proof SET proof = $3, proof_id = $4, input_prover = $5, prover = $6, prover_id = $7, generating_since = $8, updated_at = $9 WHERE batch_num = $1 AND batch_num_final = $2"
+	const addGeneratedProofSQL = "UPDATE aggregator.proof SET proof = $3, proof_id = $4, input_prover = $5, prover = $6, prover_id = $7, prover_version = $8, generating_since = $9, updated_at = $10 WHERE batch_num = $1 AND batch_num_final = $2"
 	e := p.getExecQuerier(dbTx)
 	now := time.Now().UTC().Round(time.Microsecond)
-	_, err := e.Exec(ctx, addGeneratedProofSQL, proof.BatchNumber, proof.BatchNumberFinal, proof.Proof, proof.ProofID, proof.InputProver, proof.Prover, proof.ProverID, proof.GeneratingSince, now)
+	_, err := e.Exec(ctx, addGeneratedProofSQL, proof.BatchNumber, proof.BatchNumberFinal, proof.Proof, proof.ProofID, proof.InputProver, proof.Prover, proof.ProverID, proof.ProverVersion, proof.GeneratingSince, now)
 	return err
 }
 
@@ -225,3 +264,70 @@ func toPostgresInterval(duration string) (string, error) {
 
 	return fmt.Sprintf("%s %s", duration[:len(duration)-1], pgUnit), nil
 }
+
+// GetOldestProofToAggregate returns the generated proof above the last
+// verified batch that has been waiting the longest to be aggregated.
+func (p *PostgresStorage) GetOldestProofToAggregate(ctx context.Context, lastVerfiedBatchNumber uint64, dbTx pgx.Tx) (*state.Proof, error) {
+	const getOldestProofToAggregateSQL = `
+		SELECT 
+			p.batch_num, 
+			p.batch_num_final,
+			p.proof,
+			p.proof_id,
+			p.input_prover,
+			p.prover,
+			p.prover_id,
+			p.prover_version,
+			p.generating_since,
+			p.created_at,
+			p.updated_at
+		FROM aggregator.proof p
+		WHERE p.batch_num > $1 AND p.generating_since IS NULL AND p.proof IS NOT NULL
+		ORDER BY p.updated_at ASC
+		LIMIT 1
+		`
+
+	var proof *state.Proof = &state.Proof{}
+
+	e := p.getExecQuerier(dbTx)
+	row := e.QueryRow(ctx, getOldestProofToAggregateSQL, lastVerfiedBatchNumber)
+	err := row.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proof.Proof, &proof.ProofID, &proof.InputProver, &proof.Prover, &proof.ProverID, &proof.ProverVersion, &proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, state.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return proof, err
+}
+
+// GetProofs returns up to limit proofs ending at or after the given batch
+// number, in ascending order. The proofs and their inputs are not loaded.
+func (p *PostgresStorage) GetProofs(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Proof, error) {
+	const getProofsSQL = `
+		SELECT batch_num, batch_num_final, proof_id, prover, prover_id, prover_version, generating_since, created_at, updated_at
+		FROM aggregator.proof
+		WHERE batch_num_final >= $1
+		ORDER BY batch_num ASC, batch_num_final ASC
+		LIMIT $2
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getProofsSQL, fromBatchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	proofs := []state.Proof{}
+	for rows.Next() {
+		var proof state.Proof
+		err := rows.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proof.ProofID, &proof.Prover, &proof.ProverID, &proof.ProverVersion,
+			&proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs, rows.Err()
+}
@@ -0,0 +1,71 @@
+package pgstatestorage
+
+import (
+	"context"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+// AddFinalProofPublicInputs stores the public inputs of a final proof,
+// replacing the ones of a previous final proof of the same batch range
+func (p *PostgresStorage) AddFinalProofPublicInputs(ctx context.Context, publicInputs *state.FinalProofPublicInputs, dbTx pgx.Tx) error {
+	const addFinalProofPublicInputsSQL = `
+		INSERT INTO aggregator.final_proof_public_inputs (batch_num, batch_num_final, proof_id, old_state_root, new_state_root,
+			old_acc_input_hash, new_acc_input_hash, new_local_exit_root, chain_id, fork_id, aggregator_addr)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (batch_num, batch_num_final) DO UPDATE SET
+			proof_id = $3, old_state_root = $4, new_state_root = $5, old_acc_input_hash = $6, new_acc_input_hash = $7,
+			new_local_exit_root = $8, chain_id = $9, fork_id = $10, aggregator_addr = $11, created_at = now()
+		RETURNING created_at
+		`
+	e := p.getExecQuerier(dbTx)
+	return e.QueryRow(ctx, addFinalProofPublicInputsSQL, publicInputs.BatchNumber, publicInputs.BatchNumberFinal, publicInputs.ProofID,
+		publicInputs.OldStateRoot.String(), publicInputs.NewStateRoot.String(), publicInputs.OldAccInputHash.String(),
+		publicInputs.NewAccInputHash.String(), publicInputs.NewLocalExitRoot.String(), publicInputs.ChainID, publicInputs.ForkID,
+		publicInputs.AggregatorAddr.String()).Scan(&publicInputs.CreatedAt)
+}
+
+// GetFinalProofPublicInputs returns the public inputs of the latest final
+// proofs, newest first. If batchNumber is not 0 only the ones of the final
+// proofs including that batch are returned.
+func (p *PostgresStorage) GetFinalProofPublicInputs(ctx context.Context, batchNumber uint64, limit uint64, dbTx pgx.Tx) ([]state.FinalProofPublicInputs, error) {
+	const getFinalProofPublicInputsSQL = `
+		SELECT batch_num, batch_num_final, proof_id, old_state_root, new_state_root, old_acc_input_hash, new_acc_input_hash,
+			new_local_exit_root, chain_id, fork_id, aggregator_addr, created_at
+		FROM aggregator.final_proof_public_inputs
+		WHERE $1 = 0 OR (batch_num <= $1 AND batch_num_final >= $1)
+		ORDER BY batch_num_final DESC
+		LIMIT $2
+		`
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getFinalProofPublicInputsSQL, batchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publicInputsList := []state.FinalProofPublicInputs{}
+	for rows.Next() {
+		var (
+			publicInputs                                      state.FinalProofPublicInputs
+			oldStateRoot, newStateRoot, oldAccInputHash       string
+			newAccInputHash, newLocalExitRoot, aggregatorAddr string
+		)
+		err := rows.Scan(&publicInputs.BatchNumber, &publicInputs.BatchNumberFinal, &publicInputs.ProofID, &oldStateRoot, &newStateRoot,
+			&oldAccInputHash, &newAccInputHash, &newLocalExitRoot, &publicInputs.ChainID, &publicInputs.ForkID, &aggregatorAddr,
+			&publicInputs.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		publicInputs.OldStateRoot = common.HexToHash(oldStateRoot)
+		publicInputs.NewStateRoot = common.HexToHash(newStateRoot)
+		publicInputs.OldAccInputHash = common.HexToHash(oldAccInputHash)
+		publicInputs.NewAccInputHash = common.HexToHash(newAccInputHash)
+		publicInputs.NewLocalExitRoot = common.HexToHash(newLocalExitRoot)
+		publicInputs.AggregatorAddr = common.HexToAddress(aggregatorAddr)
+		publicInputsList = append(publicInputsList, publicInputs)
+	}
+	return publicInputsList, rows.Err()
+}
@@ -15,3 +15,25 @@ func (p *PostgresStorage) AddSequence(ctx context.Context, sequence state.Sequen
 	_, err := e.Exec(ctx, addSequenceSQL, sequence.FromBatchNumber, sequence.ToBatchNumber)
 	return err
 }
+
+// GetSequences returns up to limit sequences ending at or after the given
+// batch number, in ascending order
+func (p *PostgresStorage) GetSequences(ctx context.Context, fromBatchNumber, limit uint64, dbTx pgx.Tx) ([]state.Sequence, error) {
+	const getSequencesSQL = "SELECT from_batch_num, to_batch_num FROM aggregator.sequence WHERE to_batch_num >= $1 ORDER BY from_batch_num ASC LIMIT $2"
+	e := p.getExecQuerier(dbTx)
+	rows, err := e.Query(ctx, getSequencesSQL, fromBatchNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sequences := []state.Sequence{}
+	for rows.Next() {
+		var sequence state.Sequence
+		if err := rows.Scan(&sequence.FromBatchNumber, &sequence.ToBatchNumber); err != nil {
+			return nil, err
+		}
+		sequences = append(sequences, sequence)
+	}
+	return sequences, rows.Err()
+}
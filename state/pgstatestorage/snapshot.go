@@ -0,0 +1,145 @@
+package pgstatestorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-aggregator/state"
+	"github.com/jackc/pgx/v4"
+)
+
+// ExportSnapshot reads the batches, sequences and proofs stored in the state.
+// The dbTx should be a repeatable read transaction for the snapshot to be
+// consistent while the aggregator is running.
+func (p *PostgresStorage) ExportSnapshot(ctx context.Context, dbTx pgx.Tx) (*state.Snapshot, error) {
+	const (
+		getBatchesSQL   = "SELECT batch, datastream FROM aggregator.batch ORDER BY batch_num"
+		getSequencesSQL = "SELECT from_batch_num, to_batch_num FROM aggregator.sequence ORDER BY from_batch_num"
+		getProofsSQL    = `
+			SELECT batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, prover_version, generating_since, created_at, updated_at
+			FROM aggregator.proof ORDER BY batch_num, batch_num_final`
+	)
+	e := p.getExecQuerier(dbTx)
+
+	snapshot := &state.Snapshot{
+		Version:   state.SnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	rows, err := e.Query(ctx, getBatchesSQL)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var batch state.SnapshotBatch
+		if err := rows.Scan(&batch.Batch, &batch.Datastream); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		snapshot.Batches = append(snapshot.Batches, batch)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = e.Query(ctx, getSequencesSQL)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var sequence state.Sequence
+		if err := rows.Scan(&sequence.FromBatchNumber, &sequence.ToBatchNumber); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		snapshot.Sequences = append(snapshot.Sequences, sequence)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = e.Query(ctx, getProofsSQL)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			proof      state.Proof
+			proofBlob  *string
+			inputProof *string
+		)
+		err := rows.Scan(&proof.BatchNumber, &proof.BatchNumberFinal, &proofBlob, &proof.ProofID, &inputProof,
+			&proof.Prover, &proof.ProverID, &proof.ProverVersion, &proof.GeneratingSince, &proof.CreatedAt, &proof.UpdatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if proofBlob != nil {
+			proof.Proof = *proofBlob
+		}
+		if inputProof != nil {
+			proof.InputProver = *inputProof
+		}
+		snapshot.Proofs = append(snapshot.Proofs, proof)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// ImportSnapshot stores the batches, sequences and proofs of a snapshot. The
+// state must be empty. Proofs being generated when the snapshot was taken are
+// skipped so they are generated again, and the ones locked to be aggregated
+// are imported unlocked.
+func (p *PostgresStorage) ImportSnapshot(ctx context.Context, snapshot *state.Snapshot, dbTx pgx.Tx) error {
+	const (
+		countBatchesSQL = "SELECT COUNT(*) FROM aggregator.batch"
+		addBatchSQL     = "INSERT INTO aggregator.batch (batch_num, batch, datastream) VALUES ($1, $2, $3)"
+		addSequenceSQL  = "INSERT INTO aggregator.sequence (from_batch_num, to_batch_num) VALUES ($1, $2)"
+		addProofSQL     = `
+			INSERT INTO aggregator.proof (batch_num, batch_num_final, proof, proof_id, input_prover, prover, prover_id, prover_version, generating_since, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULL, $9, $10)`
+	)
+	if snapshot.Version != state.SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, state.SnapshotVersion)
+	}
+	e := p.getExecQuerier(dbTx)
+
+	var count uint64
+	if err := e.QueryRow(ctx, countBatchesSQL).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("state is not empty, %d batches found", count)
+	}
+
+	for _, batch := range snapshot.Batches {
+		if _, err := e.Exec(ctx, addBatchSQL, batch.Batch.BatchNumber, batch.Batch, batch.Datastream); err != nil {
+			return fmt.Errorf("failed to import batch %d: %w", batch.Batch.BatchNumber, err)
+		}
+	}
+	for _, sequence := range snapshot.Sequences {
+		if _, err := e.Exec(ctx, addSequenceSQL, sequence.FromBatchNumber, sequence.ToBatchNumber); err != nil {
+			return fmt.Errorf("failed to import sequence %d-%d: %w", sequence.FromBatchNumber, sequence.ToBatchNumber, err)
+		}
+	}
+	for _, proof := range snapshot.Proofs {
+		if proof.GeneratingSince != nil && proof.Proof == "" {
+			// the prover generating it is not connected to the new database
+			continue
+		}
+		_, err := e.Exec(ctx, addProofSQL, proof.BatchNumber, proof.BatchNumberFinal, proof.Proof, proof.ProofID, proof.InputProver,
+			proof.Prover, proof.ProverID, proof.ProverVersion, proof.CreatedAt, proof.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to import proof %d-%d: %w", proof.BatchNumber, proof.BatchNumberFinal, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package state
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format
+const SnapshotVersion = 1
+
+// Snapshot is a portable copy of the aggregator state, used to migrate the
+// aggregator to a different database.
+type Snapshot struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Batches   []SnapshotBatch `json:"batches"`
+	Sequences []Sequence      `json:"sequences"`
+	Proofs    []Proof         `json:"proofs"`
+	// MonitoredTxs is the content of the eth tx manager persistence file
+	MonitoredTxs json.RawMessage `json:"monitoredTxs,omitempty"`
+}
+
+// SnapshotBatch is a batch and its data stream as stored in the state
+type SnapshotBatch struct {
+	Batch      *Batch `json:"batch"`
+	Datastream string `json:"datastream"`
+}
@@ -20,12 +20,12 @@ var (
 // State is an implementation of the state
 type State struct {
 	cfg Config
-	storage
+	Storage
 	eventLog *event.EventLog
 }
 
 // NewState creates a new State
-func NewState(cfg Config, storage storage, eventLog *event.EventLog) *State {
+func NewState(cfg Config, storage Storage, eventLog *event.EventLog) *State {
 	var once sync.Once
 	once.Do(func() {
 		metrics.Register()
@@ -33,7 +33,7 @@ func NewState(cfg Config, storage storage, eventLog *event.EventLog) *State {
 
 	state := &State{
 		cfg:      cfg,
-		storage:  storage,
+		Storage:  storage,
 		eventLog: eventLog,
 	}
 
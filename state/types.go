@@ -1,6 +1,7 @@
 package state
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/0xPolygonHermez/zkevm-aggregator/state/datastream"
@@ -23,6 +24,19 @@ const (
 	FORKID_ELDERBERRY_2 = 9
 )
 
+// BatchSource is where the data of a stored batch comes from
+type BatchSource string
+
+const (
+	// BatchSourceDataStream batches are received from the data stream with
+	// all their roots. It is the empty value, so the batches stored before the
+	// source was recorded are data stream ones.
+	BatchSourceDataStream BatchSource = ""
+	// BatchSourceL1 batches are reconstructed from the L1 sequencing data,
+	// their state and local exit roots are unknown until they are proven
+	BatchSourceL1 BatchSource = "l1"
+)
+
 // Batch struct
 type Batch struct {
 	BatchNumber     uint64
@@ -42,6 +56,13 @@ type Batch struct {
 	ChainID        uint64
 	ForkID         uint64
 	Type           datastream.BatchType
+	Source         BatchSource
+}
+
+// HasRoots returns true if the state and local exit roots of the batch are
+// known before proving it
+func (b *Batch) HasRoots() bool {
+	return b.Source != BatchSourceL1
 }
 
 // Sequence represents the sequence interval
@@ -61,6 +82,9 @@ type Proof struct {
 	Prover *string
 	// ProverID prover process identifier.
 	ProverID *string
+	// ProverVersion is the server version of the prover that generated the
+	// proof. Nil for the proofs stored before it was recorded.
+	ProverVersion *string
 	// GeneratingSince holds the timestamp for the moment in which the
 	// proof generation has started by a prover. Nil if the proof is not
 	// currently generating.
@@ -68,3 +92,106 @@ type Proof struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
+
+// AuditLogEntry is an operator action performed through the admin API
+type AuditLogEntry struct {
+	ID uint64 `json:"id"`
+	// Action is the identifier of the operation performed
+	Action string `json:"action"`
+	// Principal is the identity of the operator performing the action
+	Principal string `json:"principal"`
+	// Params are the parameters of the action, JSON encoded
+	Params json.RawMessage `json:"params,omitempty"`
+	// Result is "ok" or the error returned by the action
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BatchStats are the size and complexity of a batch along with the time it
+// took to prove it
+type BatchStats struct {
+	BatchNumber uint64 `json:"batchNumber"`
+	WitnessSize uint64 `json:"witnessSize"`
+	L2DataSize  uint64 `json:"l2DataSize"`
+	BlockCount  uint64 `json:"blockCount"`
+	TxCount     uint64 `json:"txCount"`
+	// GasLimit is the sum of the gas limit of the txs, the gas used is not
+	// known by the aggregator
+	GasLimit    uint64        `json:"gasLimit"`
+	ProvingTime time.Duration `json:"provingTime"`
+	Prover      string        `json:"prover"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// L1IntentStatus is the status of an intent to send a verification tx to L1
+type L1IntentStatus string
+
+const (
+	// L1IntentPending is an intent persisted before the tx is handed to the
+	// eth tx manager
+	L1IntentPending L1IntentStatus = "pending"
+	// L1IntentSent is an intent whose tx is monitored by the eth tx manager
+	L1IntentSent L1IntentStatus = "sent"
+	// L1IntentMined is an intent whose batch range has been verified on L1
+	L1IntentMined L1IntentStatus = "mined"
+	// L1IntentFailed is an intent whose tx failed
+	L1IntentFailed L1IntentStatus = "failed"
+	// L1IntentAbandoned is an intent whose tx was never broadcast, its batch
+	// range can be sent again
+	L1IntentAbandoned L1IntentStatus = "abandoned"
+)
+
+// L1Intent is the write-ahead record of a verification tx, persisted before
+// the tx is sent so it can be reconciled with L1 after a crash
+type L1Intent struct {
+	ID               uint64
+	BatchNumber      uint64
+	BatchNumberFinal uint64
+	CalldataHash     common.Hash
+	// Nonce is the nonce the tx is sent with
+	Nonce         uint64
+	MonitoredTxID *common.Hash
+	Status        L1IntentStatus
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// FailedProof is the forensic record of a proof the prover failed to generate
+// or returned malformed, kept for the prover team to reproduce the failure
+type FailedProof struct {
+	ID               uint64     `json:"id"`
+	Kind             string     `json:"kind"`
+	BatchNumber      uint64     `json:"batchNumber"`
+	BatchNumberFinal uint64     `json:"batchNumberFinal"`
+	ProofID          *string    `json:"proofId,omitempty"`
+	Prover           string     `json:"prover"`
+	ProverID         string     `json:"proverId"`
+	InputProver      string     `json:"inputProver"`
+	Error            string     `json:"error"`
+	ExportedAt       *time.Time `json:"exportedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// FinalProofPublicInputs are the public inputs a final proof has been built
+// with, kept for external verifiers to recompute and check them
+type FinalProofPublicInputs struct {
+	BatchNumber      uint64         `json:"batchNumber"`
+	BatchNumberFinal uint64         `json:"batchNumberFinal"`
+	ProofID          *string        `json:"proofId,omitempty"`
+	OldStateRoot     common.Hash    `json:"oldStateRoot"`
+	NewStateRoot     common.Hash    `json:"newStateRoot"`
+	OldAccInputHash  common.Hash    `json:"oldAccInputHash"`
+	NewAccInputHash  common.Hash    `json:"newAccInputHash"`
+	NewLocalExitRoot common.Hash    `json:"newLocalExitRoot"`
+	ChainID          uint64         `json:"chainId"`
+	ForkID           uint64         `json:"forkId"`
+	AggregatorAddr   common.Address `json:"aggregatorAddr"`
+	CreatedAt        time.Time      `json:"createdAt"`
+}
+
+// ProofPartition is a batch range partition of the proof table, holding the
+// proofs starting in [FromBatchNumber, ToBatchNumber)
+type ProofPartition struct {
+	FromBatchNumber uint64 `json:"fromBatchNumber"`
+	ToBatchNumber   uint64 `json:"toBatchNumber"`
+}
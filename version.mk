@@ -1,4 +1,6 @@
 VERSION := $(shell git describe --tags --always)
 GITREV := $(shell git rev-parse --short HEAD)
 GITBRANCH := $(shell git rev-parse --abbrev-ref HEAD)
+FORKVERSION := $(shell git describe --tags --always --match "xlayer-*")
+UPSTREAMVERSION := v0.1.0
 DATE := $(shell LANG=US date +"%a, %d %b %Y %X %z")
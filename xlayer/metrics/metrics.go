@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/0xPolygonHermez/zkevm-aggregator/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CodePathLabel identifies a code path that only exists in the X Layer fork
+type CodePathLabel string
+
+const (
+	// Prefix for the metrics of the X Layer fork specific code paths.
+	Prefix = "xlayer_"
+
+	// BuildInfoName is the name of the metric exposing the build information.
+	BuildInfoName = Prefix + "build_info"
+	// CodePathHitsName is the name of the metric counting the hits of the fork specific code paths.
+	CodePathHitsName = Prefix + "code_path_hits"
+	// CodePathLabelName is the name of the label for the code path.
+	CodePathLabelName = "path"
+
+	// AdminAPICodePath is used when the admin API serves a request
+	AdminAPICodePath CodePathLabel = "admin_api"
+	// InjectedFinalProofCodePath is used when an externally produced final proof is accepted
+	InjectedFinalProofCodePath CodePathLabel = "injected_final_proof"
+	// KeepWarmCodePath is used when a keepwarm job is sent to an idle prover
+	KeepWarmCodePath CodePathLabel = "keepwarm"
+)
+
+// Register the metrics for the X Layer fork specific code paths. The build
+// information is exposed as a constant gauge labeled with the git commit, the
+// fork version and the upstream version the fork is based on.
+func Register(gitRev, forkVersion, upstreamVersion string) {
+	gauges := []prometheus.GaugeOpts{
+		{
+			Name: BuildInfoName,
+			Help: "[XLAYER] build information",
+			ConstLabels: prometheus.Labels{
+				"git_commit":       gitRev,
+				"fork_version":     forkVersion,
+				"upstream_version": upstreamVersion,
+			},
+		},
+	}
+
+	counterVecs := []metrics.CounterVecOpts{
+		{
+			CounterOpts: prometheus.CounterOpts{
+				Name: CodePathHitsName,
+				Help: "[XLAYER] number of times a fork specific code path has been executed",
+			},
+			Labels: []string{CodePathLabelName},
+		},
+	}
+
+	metrics.RegisterGauges(gauges...)
+	metrics.RegisterCounterVecs(counterVecs...)
+	metrics.GaugeSet(BuildInfoName, 1)
+}
+
+// CodePathHit increments the counter of the given fork specific code path.
+func CodePathHit(path CodePathLabel) {
+	metrics.CounterVecInc(CodePathHitsName, string(path))
+}